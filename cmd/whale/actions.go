@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"golang.org/x/term"
+
+	"github.com/therapys/whale/internal/audit"
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// lifecycleAction is a mutating command whale can run on a set of containers.
+type lifecycleAction struct {
+	name string // as typed: stop, restart, rm
+	verb string // for the plan: stop, restart, remove
+	past string // for the result lines: stopped, restarted, removed
+	// all includes stopped containers in the target set.
+	all bool
+	run func(ctx context.Context, cli *client.Client, id string) error
+}
+
+// lifecycleActions are the bulk subcommands, keyed by name.
+var lifecycleActions = map[string]lifecycleAction{
+	"stop": {name: "stop", verb: "stop", past: "stopped", run: func(ctx context.Context, cli *client.Client, id string) error {
+		return cli.ContainerStop(ctx, id, container.StopOptions{})
+	}},
+	"restart": {name: "restart", verb: "restart", past: "restarted", all: true, run: func(ctx context.Context, cli *client.Client, id string) error {
+		return cli.ContainerRestart(ctx, id, container.StopOptions{})
+	}},
+	"rm": {name: "rm", verb: "remove", past: "removed", all: true, run: func(ctx context.Context, cli *client.Client, id string) error {
+		return cli.ContainerRemove(ctx, id, container.RemoveOptions{})
+	}},
+}
+
+// bulkOptions controls how runBulk confirms and executes.
+type bulkOptions struct {
+	filters []string
+	dryRun  bool // print the plan only
+	yes     bool // skip the confirmation prompt
+	force   bool // rm: also remove running containers
+	audit   auditConfig
+}
+
+// auditConfig locates the audit log mutating commands record into.
+type auditConfig struct {
+	path   string // --audit-log
+	reason string // --reason
+}
+
+// open opens the log before acting, so an unwritable log stops the action
+// instead of leaving it unrecorded.
+func (c auditConfig) open() (*audit.Log, error) {
+	log, err := audit.Open(c.path, c.reason)
+	if err != nil {
+		return nil, fmt.Errorf("audit log: %w", err)
+	}
+	return log, nil
+}
+
+// runBulk applies act to every container matching the filters (and
+// --where), after listing exactly what will be affected and asking for
+// confirmation. Without a filter it refuses, so a typo can't hit every
+// container on the host. It returns an error if any container failed.
+func runBulk(ctx context.Context, cli *client.Client, lister *dkr.Lister, act lifecycleAction, opts bulkOptions) error {
+	if len(opts.filters) == 0 && whereExpr == nil {
+		return fmt.Errorf("whale %s needs --filter or --where to select containers", act.name)
+	}
+	f, err := dkr.ParseFilters(opts.filters)
+	if err != nil {
+		return err
+	}
+	snaps, err := dkr.ListFiltered(ctx, lister, act.all, f)
+	if err != nil {
+		return err
+	}
+	if snaps, err = applyWhere(snaps); err != nil {
+		return err
+	}
+	targets := snaps[:0:0]
+	for _, s := range snaps {
+		if act.verb == "remove" && s.State == "running" && !opts.force {
+			fmt.Fprintf(os.Stderr, "skipping %s: running (use --force to remove running containers)\n", s.Name)
+			continue
+		}
+		targets = append(targets, s)
+	}
+	if len(targets) == 0 {
+		return errors.New("no containers match")
+	}
+
+	fmt.Printf("Will %s %d container(s):\n", act.verb, len(targets))
+	for _, s := range targets {
+		fmt.Printf("  %-30s %-12s %s\n", s.Name, shortID(s.ID), s.Status)
+	}
+	if opts.dryRun {
+		fmt.Println("Dry run: nothing was changed.")
+		return nil
+	}
+	if !opts.yes {
+		ok, err := confirm(os.Stdin, os.Stdout, "Proceed?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("aborted")
+		}
+	}
+
+	log, err := opts.audit.open()
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+	failed := 0
+	for _, s := range targets {
+		err := act.run(ctx, cli, s.ID)
+		if logErr := log.Record(act.name, s.Name, s.ID, "", err); logErr != nil {
+			slog.Warn("writing the audit log failed", "err", logErr)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: %v\n", s.Name, err)
+			continue
+		}
+		fmt.Printf("%s %s\n", act.past, s.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d container(s) failed", failed, len(targets))
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on an interactive terminal. Without one it
+// refuses rather than guessing; scripts pass --yes.
+func confirm(in *os.File, out io.Writer, question string) (bool, error) {
+	if !term.IsTerminal(int(in.Fd())) {
+		return false, errors.New("refusing to act without confirmation on a non-interactive stdin (use --yes, or --dry-run to preview)")
+	}
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	"golang.org/x/term"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/store"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// lastListPath is where the list shown for this daemon and command line is
+// cached (see showCached), or "" with --no-cache. Keying by the command line
+// keeps e.g. a filtered view from flashing another view's containers.
+var lastListPath string
+
+// cacheKey names the cache of lists collected from cli's daemon with the
+// current command line.
+func cacheKey(cli *client.Client) string {
+	sum := sha256.Sum256([]byte(cli.DaemonHost() + "\x00" + strings.Join(os.Args[1:], "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// showCached draws the last cached list on stdout, marked stale, so there
+// is something to read while the daemon is slow to answer. It returns how
+// many lines it drew (0 if nothing), for eraseCached.
+func showCached(sortKey ui.SortKey, renderOpts ui.RenderOptions) int {
+	if lastListPath == "" || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return 0
+	}
+	last, err := store.LoadLast(lastListPath)
+	if err != nil || len(last.Containers) == 0 {
+		return 0
+	}
+	ui.SortSnapshots(last.Containers, sortKey)
+	renderOpts.Stale = last.Time
+	renderOpts.Page = ui.Page{}
+	if perPage := ui.RowsPerScreen(renderOpts.Layout, os.Stdout); perPage > 0 && len(last.Containers) > perPage {
+		renderOpts.Page = ui.Page{Total: len(last.Containers)}
+		last.Containers = last.Containers[:perPage]
+	}
+	frame := &frameRecorder{}
+	if err := ui.Render(last.Containers, ui.FormatTable, renderOpts, frame); err != nil {
+		return 0
+	}
+	lines := strings.Count(frame.String(), "\n")
+	_, _ = frame.WriteTo(os.Stdout)
+	return lines
+}
+
+// eraseCached removes the lines showCached drew, just above the cursor.
+func eraseCached(w io.Writer, lines int) {
+	if lines > 0 {
+		fmt.Fprintf(w, "\x1b[%dA\x1b[J", lines)
+	}
+}
+
+// saveCached keeps snaps as the list to show at the next start.
+func saveCached(snaps []dkr.ContainerSnapshot) {
+	if lastListPath == "" {
+		return
+	}
+	if err := store.SaveLast(lastListPath, store.Snapshot{Time: time.Now(), Containers: snaps}); err != nil {
+		slog.Debug("caching the list failed", "err", err)
+	}
+}
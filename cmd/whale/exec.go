@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"golang.org/x/term"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// autoShell starts bash when the image has it and falls back to sh.
+var autoShell = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// runExec resolves query to one running container and runs cmd in it with
+// the local terminal attached (an interactive shell when cmd is empty).
+// It returns the exit code of the command. With dryRun it only reports
+// which container and command would be used; otherwise the exec is recorded
+// in the audit log.
+func runExec(ctx context.Context, cli *client.Client, lister *dkr.Lister, query string, cmd []string, dryRun bool, ac auditConfig) (int, error) {
+	snaps, err := dkr.ListContainers(ctx, lister, false)
+	if err != nil {
+		return 0, err
+	}
+	target, err := resolveContainer(snaps, query)
+	if err != nil {
+		return 0, err
+	}
+	detail := strings.Join(cmd, " ")
+	if len(cmd) == 0 {
+		cmd, detail = autoShell, "interactive shell"
+	}
+	if dryRun {
+		fmt.Printf("Would run %s in %s (%s)\n", detail, target.Name, shortID(target.ID))
+		fmt.Println("Dry run: nothing was changed.")
+		return 0, nil
+	}
+
+	log, err := ac.open()
+	if err != nil {
+		return 0, err
+	}
+	defer log.Close()
+
+	tty := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+	opts := container.ExecOptions{
+		Tty:          tty,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	}
+	if tty {
+		if w, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			opts.ConsoleSize = &[2]uint{uint(h), uint(w)}
+		}
+	}
+	created, err := cli.ContainerExecCreate(ctx, target.ID, opts)
+	if logErr := log.Record("exec", target.Name, target.ID, detail, err); logErr != nil {
+		slog.Warn("writing the audit log failed", "err", logErr)
+	}
+	if err != nil {
+		return 0, err
+	}
+	resp, err := cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{Tty: tty, ConsoleSize: opts.ConsoleSize})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Close()
+	fmt.Fprintf(os.Stderr, "exec into %s (%s)\n", target.Name, ui.TruncateID(target.ID, false))
+
+	if tty {
+		state, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return 0, err
+		}
+		defer func() { _ = term.Restore(int(os.Stdin.Fd()), state) }()
+		stop := watchResize(ctx, cli, created.ID)
+		defer stop()
+	}
+
+	// Stopped on return, so a session started from watch mode doesn't leave
+	// a reader behind that eats the next key press.
+	in := newStdinReader()
+	defer in.Close()
+	go func() {
+		_, _ = io.Copy(resp.Conn, in)
+		_ = resp.CloseWrite()
+	}()
+	if tty {
+		_, err = io.Copy(os.Stdout, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(os.Stdout, os.Stderr, resp.Reader)
+	}
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	info, err := cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return 0, err
+	}
+	return info.ExitCode, nil
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+
+	"github.com/docker/docker/client"
+)
+
+// watchResize is a no-op on Windows, which has no SIGWINCH; the session keeps
+// its initial console size.
+func watchResize(context.Context, *client.Client, string) func() {
+	return func() {}
+}
@@ -0,0 +1,8 @@
+package main
+
+// Exporters and output formats from other modules register themselves with
+// export.Register (github.com/therapys/whale/pkg/export) or render.Register
+// (github.com/therapys/whale/pkg/render) in an init function. To build one
+// into whale, blank-import its package here:
+//
+//	import _ "example.com/whale-kafka"
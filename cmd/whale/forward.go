@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/client"
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// runForward proxies a local TCP port to a port inside a container, using the
+// container's network IP. spec is "containerPort" or "containerPort:localPort".
+// It runs until ctx is cancelled (Ctrl+C), which also closes open
+// connections.
+func runForward(ctx context.Context, cli *client.Client, lister *dkr.Lister, query, spec string) error {
+	containerPort, localPort, err := parseForwardSpec(spec)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		// Docker Desktop runs containers in a VM whose network the host
+		// can't reach.
+		return fmt.Errorf("whale forward dials the container's IP, which Docker Desktop on %s doesn't route from the host; publish the port instead (docker run -p %d:%d)", runtime.GOOS, localPort, containerPort)
+	}
+	snaps, err := dkr.ListContainers(ctx, lister, false)
+	if err != nil {
+		return err
+	}
+	target, err := resolveContainer(snaps, query)
+	if err != nil {
+		return err
+	}
+	ip, err := dkr.ContainerIP(ctx, cli, target.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", target.Name, err)
+	}
+	remote := net.JoinHostPort(ip, strconv.Itoa(containerPort))
+
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort)))
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	fmt.Fprintf(os.Stderr, "forwarding %s -> %s (%s); Ctrl+C to stop\n", ln.Addr(), remote, target.Name)
+
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			proxyConn(ctx, conn, remote)
+		}()
+	}
+}
+
+// proxyConn copies bytes both ways between a local client and remote until
+// both sides are done or ctx is cancelled.
+func proxyConn(ctx context.Context, local net.Conn, remote string) {
+	defer local.Close()
+	var d net.Dialer
+	upstream, err := d.DialContext(ctx, "tcp", remote)
+	if err != nil {
+		slog.Warn("forward failed", "remote", remote, "err", err)
+		return
+	}
+	defer upstream.Close()
+	// Closing both ends unblocks the copies below.
+	stop := context.AfterFunc(ctx, func() {
+		_ = local.Close()
+		_ = upstream.Close()
+	})
+	defer stop()
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		// Half-close so the other side sees EOF but can still reply.
+		if tc, ok := dst.(*net.TCPConn); ok {
+			_ = tc.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go pipe(upstream, local)
+	go pipe(local, upstream)
+	<-done
+	<-done
+}
+
+// parseForwardSpec parses "80" or "80:8080" into container and local ports.
+// Without a local port the container port number is reused locally.
+func parseForwardSpec(spec string) (containerPort, localPort int, err error) {
+	cp, lp, hasLocal := strings.Cut(spec, ":")
+	if containerPort, err = parsePort(cp); err != nil {
+		return 0, 0, err
+	}
+	localPort = containerPort
+	if hasLocal {
+		if localPort, err = parsePort(lp); err != nil {
+			return 0, 0, err
+		}
+	}
+	return containerPort, localPort, nil
+}
+
+func parsePort(s string) (int, error) {
+	p, err := strconv.Atoi(s)
+	if err != nil || p < 1 || p > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return p, nil
+}
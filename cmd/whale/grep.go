@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// runGrep searches recent logs of all listed (and --where filtered)
+// containers for pattern. It reports whether anything matched.
+func runGrep(ctx context.Context, cli *client.Client, lister *dkr.Lister, includeAll bool, pattern string, since time.Duration) (bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	snaps, err := dkr.ListContainers(ctx, lister, includeAll)
+	if err != nil {
+		return false, err
+	}
+	if snaps, err = applyWhere(snaps); err != nil {
+		return false, err
+	}
+	matches, truncated, err := dkr.GrepLogs(ctx, cli, snaps, re, since)
+	if err != nil {
+		// Partial results are still useful; mention the failure and go on.
+		slog.Warn("some logs could not be read", "err", err)
+	}
+	if len(truncated) > 0 {
+		slog.Warn("logs too long, only their newest 4 MiB were searched (narrow --since)", "containers", strings.Join(truncated, ","))
+	}
+	ui.RenderLogMatches(os.Stdout, matches)
+	return len(matches) > 0, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/docker/docker/client"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// runHeatmap renders the container heatmap once, or with watch every
+// interval (count times when non-zero). While watching, the arrow keys (or
+// h/j/k/l) move a selection through the grid whose card is shown below it;
+// Esc clears it and q quits.
+func runHeatmap(ctx context.Context, cli *client.Client, opts dkr.CollectOptions, renderOpts ui.RenderOptions, metric ui.HeatMetric, watch bool, interval time.Duration, noClear bool, count int) error {
+	if !watch {
+		snaps, err := collectHeatmap(ctx, cli, opts, nil)
+		if err != nil {
+			return err
+		}
+		ui.RenderHeatmap(snaps, metric, -1, renderOpts, os.Stdout)
+		return nil
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	keys, restoreTerm := readKeys()
+	defer restoreTerm()
+	lastKnown := dkr.LastKnown{}
+	selected, selectedID := -1, ""
+	for n := 1; ; n++ {
+		snaps, err := collectHeatmap(ctx, cli, opts, lastKnown.Apply)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil // interrupted mid-collection
+			}
+			return err
+		}
+		// Follow the selected container, not its cell, as others come and go.
+		selected = min(selected, len(snaps)-1)
+		for i, s := range snaps {
+			if s.ID == selectedID {
+				selected = i
+			}
+		}
+		if selected >= 0 {
+			selectedID = snaps[selected].ID
+		}
+		refreshScreen(noClear)
+		ui.RenderHeatmap(snaps, metric, selected, renderOpts, os.Stdout)
+		if n == count {
+			return nil
+		}
+		for redraw := false; !redraw; {
+			select {
+			case <-ticker.C:
+				redraw = true
+			case k := <-keys:
+				cols := ui.HeatmapColumns(os.Stdout)
+				move := map[string]int{"left": -1, "h": -1, "right": 1, "l": 1, "up": -cols, "k": -cols, "down": cols, "j": cols}
+				switch d, ok := move[k]; {
+				case ok && len(snaps) > 0:
+					if selected < 0 {
+						selected = 0
+					} else if next := selected + d; next >= 0 && next < len(snaps) {
+						selected = next
+					}
+					selectedID = snaps[selected].ID
+				case k == "\x1b":
+					selected, selectedID = -1, ""
+				case k == "q" || k == "Q":
+					return nil
+				default:
+					continue
+				}
+				refreshScreen(noClear)
+				ui.RenderHeatmap(snaps, metric, selected, renderOpts, os.Stdout)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// collectHeatmap collects the containers for one heatmap frame, sorted by
+// name, with the same enrichment as the table.
+func collectHeatmap(ctx context.Context, cli *client.Client, opts dkr.CollectOptions, track func([]dkr.ContainerSnapshot)) ([]dkr.ContainerSnapshot, error) {
+	opts.Lister.Next()
+	snaps, err := dkr.CollectSnapshots(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if track != nil {
+		track(snaps)
+	}
+	debugConcurrency(opts)
+	debugStatsErrors(snaps)
+	if snaps, err = applyWhere(snaps); err != nil {
+		return nil, err
+	}
+	enrich(ctx, cli, snaps)
+	ui.SortHeatmap(snaps)
+	return snaps, nil
+}
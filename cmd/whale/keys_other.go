@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// readKeys is unsupported here; watch mode runs without key bindings.
+func readKeys() (<-chan string, func()) {
+	return nil, func() {}
+}
+
+// newStdinReader returns stdin as is; here a pending read can't be stopped.
+func newStdinReader() io.ReadCloser {
+	return io.NopCloser(os.Stdin)
+}
@@ -0,0 +1,148 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// readKeys switches the terminal to cbreak mode (no line buffering, no echo,
+// signals and output processing untouched) and delivers key presses on the
+// returned channel: printable keys as themselves, Enter as "\n", PgUp/PgDn
+// as "pgup"/"pgdn" and the arrow keys as "up", "down", "left" and "right".
+// With mouseEnabled it also reports the mouse, as parseMouse describes.
+// It returns a nil channel when stdin is not a terminal. The returned func
+// stops reading and restores the terminal; once it returns, stdin can be
+// handed to something else (an exec session) and readKeys called again.
+func readKeys() (<-chan string, func()) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, func() {}
+	}
+	old, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, func() {}
+	}
+	cbreak := *old
+	cbreak.Lflag &^= unix.ICANON | unix.ECHO
+	cbreak.Cc[unix.VMIN], cbreak.Cc[unix.VTIME] = 1, 0
+	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, &cbreak); err != nil {
+		return nil, func() {}
+	}
+	if mouseEnabled {
+		_, _ = os.Stdout.WriteString(mouseOn)
+	}
+	in := newStdinReader()
+	stopped := make(chan struct{})
+	keys := make(chan string, 8)
+	go func() {
+		defer close(stopped)
+		// Escape sequences arrive in one read, so a read is one key press,
+		// or a burst of mouse reports.
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if err != nil || n == 0 {
+				return
+			}
+			key := string(buf[:n])
+			if strings.HasPrefix(key, "\x1b[<") {
+				for _, k := range parseMouse(key) {
+					select {
+					case keys <- k:
+					default:
+					}
+				}
+				continue
+			}
+			switch key {
+			case "\r":
+				key = "\n"
+			case "\x1b[5~":
+				key = "pgup"
+			case "\x1b[6~":
+				key = "pgdn"
+			case "\x1b[A", "\x1bOA":
+				key = "up"
+			case "\x1b[B", "\x1bOB":
+				key = "down"
+			case "\x1b[C", "\x1bOC":
+				key = "right"
+			case "\x1b[D", "\x1bOD":
+				key = "left"
+			}
+			select {
+			case keys <- key:
+			default: // drop keys while a refresh is busy
+			}
+		}
+	}()
+	return keys, func() {
+		_ = in.Close()
+		<-stopped
+		if mouseEnabled {
+			_, _ = os.Stdout.WriteString(mouseOff)
+		}
+		_ = unix.IoctlSetTermios(fd, ioctlWriteTermios, old)
+	}
+}
+
+// stdinReader reads the terminal on stdin and can be stopped while waiting
+// for input, which a blocked os.Stdin.Read cannot: Close makes a pending
+// Read return io.EOF within pollInterval instead of swallowing the next key
+// press meant for whoever reads stdin next.
+type stdinReader struct {
+	fd   int
+	done chan struct{}
+}
+
+// pollInterval bounds how long Close waits for a pending Read.
+const pollInterval = 100 // ms
+
+func newStdinReader() io.ReadCloser {
+	return &stdinReader{fd: int(os.Stdin.Fd()), done: make(chan struct{})}
+}
+
+func (r *stdinReader) Read(p []byte) (int, error) {
+	for {
+		select {
+		case <-r.done:
+			return 0, io.EOF
+		default:
+		}
+		fds := []unix.PollFd{{Fd: int32(r.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, pollInterval)
+		if errors.Is(err, unix.EINTR) || n == 0 {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		n, err = unix.Read(r.fd, p)
+		if errors.Is(err, unix.EINTR) || errors.Is(err, unix.EAGAIN) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			return 0, io.EOF
+		}
+		return n, nil
+	}
+}
+
+func (r *stdinReader) Close() error {
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+	}
+	return nil
+}
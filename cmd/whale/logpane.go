@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// logPaneLines is how many of the newest log lines the log pane holds.
+const logPaneLines = 1000
+
+// logPane is the watch view's log viewer for one container, opened with l
+// from the action menu. It scrolls, follows new output, searches and wraps
+// long lines.
+type logPane struct {
+	name      string
+	lines     []string
+	truncated bool
+	// offset is how many lines the view is scrolled up from the newest.
+	offset int
+	follow bool
+	wrap   bool
+	query  string // highlighted, and what n/N jump to
+	// typing is set while the search prompt reads input.
+	typing bool
+	input  string
+	msg    string
+}
+
+// runLogPane shows s's logs on w until q or Esc is pressed or ctx is done,
+// and returns a result line for the watch view when reading them fails.
+func runLogPane(ctx context.Context, cli *client.Client, w io.Writer, s dkr.ContainerSnapshot, keys <-chan string) string {
+	p := &logPane{name: s.Name, follow: true}
+	fetch := func() error {
+		cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		lines, truncated, err := dkr.ReadLogLines(cctx, cli, s.ID, container.LogsOptions{Tail: strconv.Itoa(logPaneLines)})
+		if err != nil {
+			return fmt.Errorf("logs %s: %w", s.Name, err)
+		}
+		p.lines, p.truncated = lines, truncated
+		return nil
+	}
+	if err := fetch(); err != nil {
+		return err.Error()
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		p.draw(w)
+		select {
+		case <-ctx.Done():
+			return ""
+		case <-ticker.C:
+			if !p.follow {
+				continue
+			}
+			if err := fetch(); err != nil {
+				p.msg = err.Error()
+			}
+		case k := <-keys:
+			if !p.handleKey(k) {
+				return ""
+			}
+		}
+	}
+}
+
+// handleKey applies a key press and returns false when the pane closes.
+func (p *logPane) handleKey(k string) bool {
+	p.msg = ""
+	if p.typing {
+		switch k {
+		case "\n":
+			p.typing, p.query = false, p.input
+			p.jump(-1, true)
+		case "\x1b":
+			p.typing = false
+		case "\x7f", "\b":
+			if r := []rune(p.input); len(r) > 0 {
+				p.input = string(r[:len(r)-1])
+			}
+		default:
+			// Named keys (arrows, paging) are words; typed text is one rune.
+			if r, n := utf8.DecodeRuneInString(k); n == len(k) && unicode.IsPrint(r) {
+				p.input += k
+			}
+		}
+		return true
+	}
+	page := max(p.height()-1, 1)
+	switch k {
+	case "q", "Q", "\x1b":
+		return false
+	case "f":
+		p.follow = !p.follow
+		if p.follow {
+			p.offset = 0
+		}
+	case "w":
+		p.wrap = !p.wrap
+	case "/":
+		p.typing, p.input = true, ""
+	case "n":
+		p.jump(-1, false)
+	case "N":
+		p.jump(1, false)
+	case "up", "k", "wheelup":
+		p.scroll(1)
+	case "down", "j", "wheeldown":
+		p.scroll(-1)
+	case "pgup", "b":
+		p.scroll(page)
+	case "pgdn", " ":
+		p.scroll(-page)
+	case "g":
+		p.scroll(len(p.lines))
+	case "G":
+		p.scroll(-len(p.lines))
+	}
+	return true
+}
+
+// scroll moves the view d lines towards older output (d < 0: newer).
+// Scrolling up stops following; reaching the bottom doesn't resume it.
+func (p *logPane) scroll(d int) {
+	p.offset = min(max(p.offset+d, 0), max(len(p.lines)-1, 0))
+	if d > 0 && p.offset > 0 {
+		p.follow = false
+	}
+}
+
+// jump scrolls to the next line containing the query in direction dir
+// (-1 older, 1 newer), starting at the bottom line of the view when
+// inclusive is set and past it otherwise.
+func (p *logPane) jump(dir int, inclusive bool) {
+	if p.query == "" {
+		return
+	}
+	i := len(p.lines) - 1 - p.offset
+	if !inclusive {
+		i -= dir
+	}
+	for ; i >= 0 && i < len(p.lines); i -= dir {
+		if strings.Contains(text.StripEscape(p.lines[i]), p.query) {
+			p.offset = len(p.lines) - 1 - i
+			p.follow = p.follow && p.offset == 0
+			return
+		}
+	}
+	p.msg = fmt.Sprintf("%q not found", p.query)
+}
+
+// size returns the terminal's size, or 80x24 when it is unknown.
+func (p *logPane) size() (int, int) {
+	if w, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 && h > 0 {
+		return w, h
+	}
+	return 80, 24
+}
+
+// height is how many rows the log lines get below the title and above the
+// key help.
+func (p *logPane) height() int {
+	_, h := p.size()
+	return max(h-2, 1)
+}
+
+// draw redraws the pane: the view ends at the line offset lines above the
+// newest and is filled upwards, wrapped or cut to the terminal width.
+func (p *logPane) draw(w io.Writer) {
+	width, _ := p.size()
+	height := p.height()
+	var rows []string
+	for i := len(p.lines) - 1 - p.offset; i >= 0 && len(rows) < height; i-- {
+		line := strings.ReplaceAll(text.StripEscape(p.lines[i]), "\t", "    ")
+		line = strings.Map(func(r rune) rune {
+			if r < 0x20 || r == 0x7f {
+				return -1
+			}
+			return r
+		}, line)
+		var parts []string
+		if p.wrap && text.RuneWidthWithoutEscSequences(line) > width {
+			parts = strings.Split(text.WrapHard(line, width), "\n")
+		} else {
+			parts = []string{ui.TruncateName(line, false, width)}
+		}
+		for j := len(parts) - 1; j >= 0 && len(rows) < height; j-- {
+			rows = append(rows, p.highlight(parts[j]))
+		}
+	}
+
+	var buf bytes.Buffer
+	title := "whale — logs of " + p.name
+	if p.follow {
+		title += " (following)"
+	}
+	if p.truncated {
+		title += fmt.Sprintf(" (newest %d lines)", len(p.lines))
+	}
+	buf.WriteString(text.Colors{text.Bold, text.FgHiWhite}.Sprint(title) + "\n")
+	for range height - len(rows) {
+		buf.WriteString("\n")
+	}
+	for i := len(rows) - 1; i >= 0; i-- {
+		buf.WriteString(rows[i] + "\n")
+	}
+	switch {
+	case p.typing:
+		buf.WriteString("/" + p.input)
+	case p.msg != "":
+		buf.WriteString(text.Colors{text.FgYellow}.Sprint(p.msg))
+	default:
+		onOff := map[bool]string{true: "on", false: "off"}
+		buf.WriteString(text.Colors{text.Faint}.Sprintf("f follow: %s  w wrap: %s  / search  n/N older/newer match  ↑↓ PgUp/PgDn g/G scroll  q back",
+			onOff[p.follow], onOff[p.wrap]))
+	}
+	ui.ClearScreen(w)
+	_, _ = buf.WriteTo(w)
+}
+
+// highlight marks the query's occurrences in a display row.
+func (p *logPane) highlight(row string) string {
+	if p.query == "" {
+		return row
+	}
+	mark := text.Colors{text.BgYellow, text.FgBlack}.Sprint(p.query)
+	return strings.ReplaceAll(row, p.query, mark)
+}
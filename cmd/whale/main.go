@@ -2,45 +2,257 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/docker/docker/client"
+	"github.com/therapys/whale/internal/audit"
+	"github.com/therapys/whale/internal/config"
 	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/filter"
+	"github.com/therapys/whale/internal/plugin"
+	"github.com/therapys/whale/internal/scan"
+	"github.com/therapys/whale/internal/scrape"
+	"github.com/therapys/whale/internal/store"
 	"github.com/therapys/whale/internal/ui"
+	"github.com/therapys/whale/pkg/export"
 )
 
 func main() {
-	// Subcommand-like dispatch: whale [net] [flags]
-	netMode := false
-	if len(os.Args) > 1 && os.Args[1] == "net" {
-		netMode = true
-		// Remove subcommand before parsing flags
-		os.Args = append([]string{os.Args[0]}, os.Args[2:]...)
+	// Subcommand-like dispatch: whale [net|mounts|exposed|images|outdated|scan|drift|stop|restart|rm|snapshot|diff|grep|exec|forward|wait|top|heatmap|watch] [flags] [args]
+	mode := ""
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "net", "mounts", "exposed", "images", "outdated", "scan", "drift", "stop", "restart", "rm", "snapshot", "diff", "grep", "exec", "forward", "wait", "top", "heatmap", "watch":
+			mode = os.Args[1]
+			// Remove subcommand before parsing flags
+			os.Args = append([]string{os.Args[0]}, os.Args[2:]...)
+		}
 	}
 
 	// Flags
 	includeAll := flag.Bool("all", false, "Include stopped containers in the list")
-	sortKey := flag.String("sort", "cpu", "Sort by: cpu, mem, name")
-	format := flag.String("format", "table", "Output format: table or json")
+	sortKey := flag.String("sort", "cpu", "Sort by: cpu, mem, name, cpu-time, net-rate, disk-rate (rates need --watch)")
+	format := flag.String("format", "table", "Output format: "+strings.Join(ui.RendererNames(), ", ")+"; template takes a Go template, e.g. 'template:{{.Name}} {{.CPUPercent}}'")
 	noTrunc := flag.Bool("no-trunc", false, "Do not truncate container IDs")
+	layout := flag.String("layout", "auto", "Table layout: auto (cards below 80 columns), table, or cards")
+	timeStyle := flag.String("time", "clock", "Timestamps in titles and event lines: clock (time of day), absolute (date, time and zone) or relative (2m ago; titles show time since start)")
+	utc := flag.Bool("utc", false, "Write clock and absolute timestamps in UTC, for comparing against server logs")
+	cpuScale := flag.String("cpu-scale", "percent", "What a full CPU bar means: percent (one core), cores (every CPU the container can use) or limit (its --cpus limit, else cores)")
+	heat := flag.String("heat", "cpu", "What shades the cells of `whale heatmap`: cpu or mem")
+	columnPriority := flag.String("column-priority", "", "Comma-separated table columns, most important first (e.g. NAME,cpu,MEM,STATUS); the rest shrink and drop first on narrow terminals")
+	noStats := flag.Bool("no-stats", false, "Skip stats and list name, ID, status, image and ports only (fast on large hosts)")
+	showImage := flag.Bool("image", false, "Add an IMAGE column with the image reference, registry digest and image age")
+	var imageMaxAge ageValue
+	flag.Var(&imageMaxAge, "image-max-age", "Highlight containers whose image was built longer ago than this (e.g. 90d or 720h); implies --image")
+	showCPUTime := flag.Bool("cpu-time", false, "Add a CPU TIME column with the CPU time each container has used since it started")
+	showMounts := flag.Bool("mounts", false, "Add a MNTS column with the number of volumes and bind mounts (see `whale mounts` for details)")
+	dangling := flag.Bool("dangling", false, "In `whale images`, list only untagged images and what removing them would reclaim")
+	auditMounts := flag.Bool("audit", false, "In `whale mounts`, list only bind mounts of sensitive host paths (/, /etc, the Docker socket, home directories...); in `whale exposed`, only database and admin ports bound on all interfaces. Exit 1 if any are found")
+	volumeSize := flag.Bool("volume-size", false, "In `whale mounts`, measure the data in each named volume (may be slow on large volumes)")
+	showCommand := flag.Bool("command", false, "Add a COMMAND column (full command with --no-trunc)")
+	concurrency := flag.Int("concurrency", dkr.DefaultConcurrency, "Maximum parallel stats requests to the Docker daemon (adaptive when unset)")
+	collector := flag.String("collector", "api", "Metrics source: api (Docker stats API) or cgroup (read /sys/fs/cgroup directly; Linux, cgroup v2, on the Docker host)")
+	sample := flag.Duration("sample", 0, "Compute CPU% from two readings this far apart (e.g. 1s) in one-shot mode")
+	debug := flag.Bool("debug", false, "Print diagnostic details to stderr (same as --log-level=debug)")
+	logLevel := flag.String("log-level", "warn", "Log to stderr from this level: debug, info, warn or error")
+	logFormat := flag.String("log-format", "plain", "Log format: plain (for reading), text (key=value with timestamps) or json (one object per line, for log collectors)")
+	profile := flag.Bool("profile", false, "Report list, stats and render timings (slowest containers first) to stderr")
 	watch := flag.Bool("watch", false, "Continuously refresh and stream live stats")
 	interval := flag.Duration("interval", 2*time.Second, "Refresh interval for --watch")
-	flag.Parse()
+	flapThresholdFlag := flag.Int("flap-threshold", 3, "In --watch, mark containers restarting more than this many times within --flap-window as flapping")
+	flapWindowFlag := flag.Duration("flap-window", 5*time.Minute, "Window for --flap-threshold")
+	until := flag.String("until", "", `Stop --watch when a condition is met, e.g. 'cpu < 5 for 30s' or 'container db exited'`)
+	sessionIO := flag.Bool("session-io", false, "With --watch, show NET I/O and BLOCK I/O accumulated since whale started instead of since container start")
+	probe := flag.Bool("probe", false, "In `whale net check`, also test reachability from the first container with ping (run inside it)")
+	probePort := flag.Int("port", 0, "In `whale net check`, test this TCP port instead of ping (implies --probe)")
+	count := flag.Int("count", 0, "Refresh exactly N times, then exit (like vmstat 2 5); implies --watch")
+	maxRuntime := flag.Duration("max-runtime", 0, "Stop whatever whale is doing after this long (e.g. 1h) and exit with status 124; watch modes print their summary first")
+	watchFor := flag.Duration("duration", 0, "With --watch, exit after this long (e.g. 5m), printing the session summary")
+	sessionOut := flag.String("session-out", "", "With --watch, write the session summary and per-refresh time series to this JSON file on exit")
+	noClear := flag.Bool("no-clear", false, "With --watch, append each refresh with a timestamp instead of clearing the screen")
+	tag := flag.String("tag", "", "Label for `whale snapshot`")
+	anomalySigma := flag.Float64("anomaly-sigma", 0, "Mark CPU and memory readings more than this many standard deviations from the container's baseline (history from --store plus this session); 0 disables")
+	storePath := flag.String("store", store.DefaultPath, "Snapshot file used by `whale snapshot` and `whale diff`")
+	where := flag.String("where", "", `Only show containers matching an expression, e.g. 'cpu_percent > 20 && has_label("env", "prod")'`)
+	metricList := flag.String("metrics", "", "Comma-separated Prometheus metrics to scrape from containers labeled "+scrape.LabelPort)
+	logErrors := flag.Duration("log-errors", 0, "Add an ERRORS column counting log lines from this window (e.g. 60s) that match --log-error-pattern")
+	logErrorPattern := flag.String("log-error-pattern", `(?i)\b(error|fatal|panic|exception)\b`, "Regular expression for --log-errors")
+	labelPrefixes := flag.String("label-prefix", "", "Comma-separated label key prefixes to include in JSON output (default: all labels)")
+	hostNetIO := flag.Bool("host-net-io", false, "Show the host's network totals (from /proc/net/dev, Linux) for --network host containers, marked (host)")
+	netDetails := flag.Bool("details", false, "whale net: show every network in detail, as whale net <network> does")
+	noCache := flag.Bool("no-cache", false, "Don't show the last list (marked cached) while the first collection runs, nor save it for next time")
+	thousands := flag.Bool("thousands", false, "Group digits of large numbers (1,234,567), with the separators and decimal mark of your locale (LC_ALL, LC_NUMERIC or LANG)")
+	mouseFlag := flag.Bool("mouse", false, "In --watch, click rows to select, click column headers to sort and scroll to page (Shift+drag still selects text in most terminals)")
+	connsFlag := flag.Bool("conns", false, "Add a CONNS column with each container's established TCP connections (from /proc on the Docker host, else by exec)")
+	fdsFlag := flag.Bool("fds", false, "Add an FDS column with open file descriptors against the open-files limit (Linux, run on the Docker host as root)")
+	zombiesFlag := flag.Bool("zombies", false, "Check each container for defunct (zombie) processes via docker top")
+	showLastLog := flag.Bool("show-last-log", false, "Add a LAST LOG column with each container's most recent log line")
+	scanTool := flag.String("scan", "", "Add a CVES column by scanning running images with trivy or grype (results cached for 24h); `whale scan` shows details")
+	pluginList := flag.String("plugins", "", "Comma-separated column plugin executables (see README)")
+	exportList := flag.String("export", "", "Comma-separated exporters run after each collection, e.g. jsonl:/tmp/whale.jsonl")
+	configPath := flag.String("config", config.DefaultPath(), "Path to the whale config file")
+	view := flag.String("view", "", "Apply a named view (flag set) from the config file")
+	since := flag.Duration("since", 15*time.Minute, "How far back `whale grep` searches logs")
+	composeFile := flag.String("f", "", "Compose file for `whale drift` (default: compose's own lookup in the current directory)")
+	filterList := flag.String("filter", "", "Comma-separated key=value container filters for `whale wait`, `stop`, `restart` and `rm` (project=NAME, label=K=V, name=...)")
+	dryRun := flag.Bool("dry-run", false, "For commands that act on containers (stop, restart, rm, exec), print the plan without executing it")
+	yes := flag.Bool("yes", false, "With `whale stop`, `restart` or `rm`, skip the confirmation prompt")
+	force := flag.Bool("force", false, "With `whale rm`, also remove running containers")
+	readOnly := flag.Bool("read-only", false, "Disable every command that changes containers (stop, restart, rm, exec); once enabled by the config file or environment it can't be turned off on the command line")
+	auditLog := flag.String("audit-log", audit.DefaultPath(), "File that stop, restart, rm and exec append a JSON line to for every action (who, when, what, why)")
+	reason := flag.String("reason", "", "Why an action is taken, recorded in the audit log")
+	healthy := flag.Bool("healthy", false, "With `whale wait`, also require passing healthchecks")
+	waitTimeout := flag.Duration("timeout", 60*time.Second, "How long `whale wait` waits before failing (0 waits forever)")
+	args := parseInterspersed()
+	if err := applyEnv(); err != nil {
+		fatal(err)
+	}
+	cfg, err := applyConfig(*configPath, *view)
+	if err != nil {
+		fatal(err)
+	}
+	switch ui.Layout(strings.ToLower(*layout)) {
+	case ui.LayoutAuto, ui.LayoutTable, ui.LayoutCards:
+	default:
+		fatal(fmt.Errorf("--layout: unknown layout %q (want auto, table or cards)", *layout))
+	}
+	switch ui.CPUScale(strings.ToLower(*cpuScale)) {
+	case ui.CPUScalePercent, ui.CPUScaleCores:
+	case ui.CPUScaleLimit:
+		cpuLimits = dkr.NewCPULimits()
+	default:
+		fatal(fmt.Errorf("--cpu-scale: unknown scale %q (want percent, cores or limit)", *cpuScale))
+	}
+	switch style := ui.TimeStyle(strings.ToLower(*timeStyle)); style {
+	case ui.TimeClock, ui.TimeAbsolute, ui.TimeRelative:
+		ui.SetTimeFormat(style, *utc)
+	default:
+		fatal(fmt.Errorf("--time: unknown style %q (want clock, absolute or relative)", *timeStyle))
+	}
+	if _, err := ui.NewRenderer(*format); err != nil {
+		fatal(fmt.Errorf("--format: %w", err))
+	}
+	if *count < 0 {
+		fatal(fmt.Errorf("--count: must not be negative"))
+	}
+	if *count > 0 || mode == "watch" {
+		// A fixed number of refreshes is a bounded watch; `whale watch` is
+		// --watch.
+		*watch = true
+	}
+	if err := checkViewFormat(mode, *watch, parseOutputFormat(*format)); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(2)
+	}
+	collectOpts := dkr.CollectOptions{IncludeAll: *includeAll, Concurrency: *concurrency, NoStats: *noStats, Exits: dkr.NewExitCache()}
+	if *noStats && !flagSet("sort") {
+		// No metrics to rank by.
+		*sortKey = "name"
+	}
+	if !flagSet("concurrency") {
+		// No explicit value: tune concurrency from daemon latency instead.
+		collectOpts.Limiter = dkr.NewAdaptiveLimiter(dkr.DefaultConcurrency, 1, dkr.MaxInFlight, dkr.SlowStatsCall)
+	}
+	renderOpts := ui.RenderOptions{NoTrunc: *noTrunc, NoStats: *noStats, ShowCommand: *showCommand, ShowImage: *showImage || imageMaxAge > 0, ImageMaxAge: time.Duration(imageMaxAge), ShowMounts: *showMounts, ShowFDs: *fdsFlag, ShowConns: *connsFlag, ShowCPUTime: *showCPUTime || strings.EqualFold(*sortKey, "cpu-time"), ShowLogErrors: *logErrors > 0, ShowLastLog: *showLastLog, LabelPrefixes: splitList(*labelPrefixes), ColumnPriority: splitList(*columnPriority), Layout: ui.Layout(strings.ToLower(*layout)), CPUScale: ui.CPUScale(strings.ToLower(*cpuScale))}
+	lastLog = *showLastLog
+	quickActions = quickActionConfig{audit: auditConfig{*auditLog, *reason}, readOnly: *readOnly}
+	checkZombies = *zombiesFlag
+	countFDs = *fdsFlag
+	countConns = *connsFlag
+	mouseEnabled = *mouseFlag
+	if *thousands {
+		ui.SetNumberFormat(ui.LocaleNumberFormat(os.Getenv))
+	}
+	fillHostNet = *hostNetIO
+	if *logErrors > 0 {
+		re, err := regexp.Compile(*logErrorPattern)
+		if err != nil {
+			fatal(fmt.Errorf("--log-error-pattern: %w", err))
+		}
+		logErrorWindow, logErrorRe = *logErrors, re
+	}
+	if *debug {
+		*logLevel = "debug"
+	}
+	if err := setupLogging(*logLevel, *logFormat); err != nil {
+		fatal(err)
+	}
+	flapThreshold, flapWindow = *flapThresholdFlag, *flapWindowFlag
+	if *where != "" {
+		expr, err := filter.Compile(*where)
+		if err != nil {
+			fatal(err)
+		}
+		whereExpr = expr
+	}
+	plugins = splitList(*pluginList)
+	for _, spec := range append(splitList(*exportList), cfg.Exporters...) {
+		e, err := export.New(spec)
+		if err != nil {
+			fatal(err)
+		}
+		exporters = append(exporters, e)
+	}
+	if *profile {
+		collectOpts.Timings = &dkr.CollectTimings{}
+	}
+	switch strings.ToLower(*collector) {
+	case "api":
+	case "cgroup":
+		cg, err := dkr.NewCgroupCollector()
+		if err != nil {
+			fatal(err)
+		}
+		collectOpts.Cgroup = cg
+	default:
+		fatal(fmt.Errorf("--collector: unknown collector %q (want api or cgroup)", *collector))
+	}
+
+	if mode == "diff" {
+		// Reads the snapshot store only; no daemon needed.
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: whale diff <from-tag> <to-tag> [--store file] [--format json]")
+			os.Exit(2)
+		}
+		if err := runDiff(args[0], args[1], *storePath, parseOutputFormat(*format), *noTrunc); err != nil {
+			fatal(err)
+		}
+		return
+	}
 
 	var ctx context.Context
 	var cancel context.CancelFunc
-	if *watch {
+	if _, bulk := lifecycleActions[mode]; bulk || *watch || mode == "exec" || mode == "forward" || mode == "wait" || mode == "top" {
+		// Long-running modes (bulk actions wait for a prompt and for each
+		// container to stop): no overall timeout, stop on Ctrl+C/SIGTERM.
 		ctx, cancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	} else {
 		ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
 	}
 	defer cancel()
+	if *maxRuntime > 0 {
+		var stop context.CancelFunc
+		ctx, stop = context.WithTimeoutCause(ctx, *maxRuntime, errMaxRuntime)
+		defer stop()
+		runCtx, runLimit = ctx, *maxRuntime
+		// Runs first among the deferred calls, before stop clears the cause.
+		defer exitIfMaxRuntime()
+	}
+
+	if *readOnly && !*dryRun && (mode == "exec" || lifecycleActions[mode].run != nil) {
+		fatal(fmt.Errorf("whale %s is disabled in read-only mode (--dry-run still shows the plan)", mode))
+	}
 
 	// Docker client
 	cli, err := dkr.NewClient(ctx)
@@ -48,52 +260,418 @@ func main() {
 		fatal(err)
 	}
 	defer cli.Close()
+	// One container list per refresh, shared by the views that need it.
+	collectOpts.Lister = dkr.NewLister(cli)
+	collectOpts.Collector = dkr.NewDockerCollector(cli, collectOpts.Lister)
+	if !*noCache && (mode == "" || mode == "watch") && parseOutputFormat(*format) == ui.FormatTable {
+		lastListPath = store.LastPath(cacheKey(cli))
+	}
+	if renderOpts.ShowImage || parseOutputFormat(*format) == ui.FormatJSON || mode == "snapshot" || mode == "outdated" || len(exporters) > 0 {
+		// Only resolve digests when something will show or record them.
+		imageDigests = dkr.NewImageDigests()
+	}
+	if *scanTool != "" && mode == "" {
+		if scanner, err = scan.New(*scanTool); err != nil {
+			fatal(err)
+		}
+		// One-shot output waits for scans; watch mode fills them in.
+		scanner.Block = !*watch
+	}
+	if *anomalySigma > 0 {
+		baseline = dkr.NewBaseline(*anomalySigma, 120)
+		history, err := store.Load(*storePath)
+		if err != nil {
+			fatal(err)
+		}
+		seed := make([][]dkr.ContainerSnapshot, len(history))
+		for i, h := range history {
+			seed[i] = h.Containers
+		}
+		baseline.Seed(seed)
+	}
+	if names := splitList(*metricList); len(names) > 0 {
+		scraper = scrape.New(cli, names)
+	}
+
+	if mode == "exec" {
+		if len(args) < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: whale exec <name> [-- command...]")
+			os.Exit(2)
+		}
+		code, err := runExec(ctx, cli, collectOpts.Lister, args[0], args[1:], *dryRun, auditConfig{*auditLog, *reason})
+		if err != nil {
+			fatal(err)
+		}
+		os.Exit(code)
+	}
+
+	if mode == "forward" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: whale forward <container> <containerPort>[:localPort]")
+			os.Exit(2)
+		}
+		if err := runForward(ctx, cli, collectOpts.Lister, args[0], args[1]); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if mode == "top" {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: whale top <container> [--interval 2s] [--count N]")
+			os.Exit(2)
+		}
+		if err := runTop(ctx, cli, args[0], collectOpts, renderOpts, *interval, *noClear, *count); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if mode == "heatmap" {
+		metric := ui.HeatMetric(strings.ToLower(*heat))
+		if metric != ui.HeatCPU && metric != ui.HeatMem {
+			fatal(fmt.Errorf("--heat: unknown metric %q (want cpu or mem)", *heat))
+		}
+		if err := runHeatmap(ctx, cli, collectOpts, renderOpts, metric, *watch, *interval, *noClear, *count); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if mode == "wait" {
+		if len(args) != 0 {
+			fmt.Fprintln(os.Stderr, "Usage: whale wait [--filter project=NAME] [--healthy] [--timeout 60s]")
+			os.Exit(2)
+		}
+		if err := runWait(ctx, cli, collectOpts.Lister, splitList(*filterList), *healthy, *waitTimeout, time.Second); err != nil {
+			fatal(err)
+		}
+		return
+	}
 
-	if netMode {
+	if mode == "grep" {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: whale grep <pattern> [--since 15m] [--all] [--where expr]")
+			os.Exit(2)
+		}
+		found, err := runGrep(ctx, cli, collectOpts.Lister, collectOpts.IncludeAll, args[0], *since)
+		if err != nil {
+			fatal(err)
+		}
+		if !found {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if mode == "snapshot" {
+		if err := runSnapshot(ctx, cli, collectOpts, *tag, *storePath); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if mode == "net" && len(args) > 0 && args[0] == "resolve" {
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "Usage: whale net resolve <container> <name>")
+			os.Exit(2)
+		}
+		ok, err := runNetResolve(ctx, cli, collectOpts.Lister, args[1], args[2])
+		if err != nil {
+			fatal(err)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if mode == "net" && len(args) > 0 && args[0] == "check" {
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "Usage: whale net check <containerA> <containerB> [--probe] [--port N]")
+			os.Exit(2)
+		}
+		ok, err := runNetCheck(ctx, cli, collectOpts.Lister, args[1], args[2], *probe, *probePort)
+		if err != nil {
+			fatal(err)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if mode == "net" {
 		if *watch {
-			if strings.ToLower(*format) == "json" {
-				fmt.Fprintln(os.Stderr, "Error: --watch is not supported with --format=json for networks")
-				os.Exit(2)
+			if err := watchNetworks(ctx, cli, collectOpts, *includeAll, *noTrunc, *interval, *noClear, *count); err != nil {
+				fatal(err)
+			}
+			return
+		}
+		if len(args) == 1 {
+			d, err := dkr.InspectNetwork(ctx, cli, args[0])
+			if err != nil {
+				fatal(err)
+			}
+			if err := ui.RenderNetworkDetail(d, parseOutputFormat(*format), *noTrunc, os.Stdout); err != nil {
+				fatal(err)
+			}
+			return
+		}
+		if len(args) > 1 || *netDetails {
+			// Several networks (or all of them): inspected in parallel.
+			list, err := dkr.InspectNetworks(ctx, cli, args)
+			if rerr := ui.RenderNetworkDetails(list, parseOutputFormat(*format), *noTrunc, os.Stdout); rerr != nil {
+				fatal(rerr)
 			}
-			if err := watchNetworks(ctx, cli, *includeAll, *noTrunc, *interval); err != nil {
+			if err != nil {
 				fatal(err)
 			}
 			return
 		}
-		groups, err := dkr.CollectNetworks(ctx, cli, *includeAll)
+		groups, err := collectOpts.Collector.Networks(ctx, *includeAll)
 		if err != nil {
 			fatal(err)
 		}
-		if err := ui.RenderNetworks(groups, *noTrunc, os.Stdout); err != nil {
+		addSwarmServices(ctx, cli, groups)
+		if err := ui.RenderNetworks(groups, networkParents(ctx, cli), *noTrunc, os.Stdout); err != nil {
 			fatal(err)
 		}
 		return
 	}
 
+	if mode == "mounts" {
+		list, err := dkr.CollectMounts(ctx, cli, *includeAll)
+		if err != nil {
+			fatal(err)
+		}
+		dkr.FillTmpfsUsage(ctx, cli, list)
+		if *volumeSize {
+			sizes, err := dkr.VolumeSizes(ctx, cli)
+			if err != nil {
+				fatal(err)
+			}
+			dkr.ApplyVolumeSizes(list, sizes)
+		}
+		if *auditMounts {
+			list = flaggedMounts(list)
+		}
+		if err := ui.RenderMounts(list, parseOutputFormat(*format), *noTrunc, os.Stdout); err != nil {
+			fatal(err)
+		}
+		if *auditMounts && len(list) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if mode == "exposed" {
+		list, err := dkr.CollectExposed(ctx, cli)
+		if err != nil {
+			fatal(err)
+		}
+		if *auditMounts {
+			list = riskyPorts(list)
+		}
+		if err := ui.RenderExposed(list, parseOutputFormat(*format), *noTrunc, os.Stdout); err != nil {
+			fatal(err)
+		}
+		if *auditMounts && len(list) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if mode == "images" {
+		list, err := dkr.CollectImages(ctx, cli, *dangling)
+		if err != nil {
+			fatal(err)
+		}
+		if err := ui.RenderImages(list, parseOutputFormat(*format), *dangling, *noTrunc, os.Stdout); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if mode == "scan" {
+		tool := *scanTool
+		if tool == "" {
+			tool = "trivy"
+		}
+		sc, err := scan.New(tool)
+		if err != nil {
+			fatal(err)
+		}
+		query := ""
+		if len(args) > 0 {
+			query = args[0]
+		}
+		if err := runScan(ctx, cli, collectOpts.Lister, sc, query, parseOutputFormat(*format), *noTrunc); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if act, ok := lifecycleActions[mode]; ok {
+		opts := bulkOptions{filters: splitList(*filterList), dryRun: *dryRun, yes: *yes, force: *force, audit: auditConfig{*auditLog, *reason}}
+		if err := runBulk(ctx, cli, collectOpts.Lister, act, opts); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if mode == "drift" {
+		project, err := dkr.LoadCompose(ctx, *composeFile)
+		if err != nil {
+			fatal(err)
+		}
+		drift, err := dkr.CheckDrift(ctx, cli, project)
+		if err != nil {
+			fatal(err)
+		}
+		if err := ui.RenderDrift(project.Name, drift, parseOutputFormat(*format), *noTrunc, os.Stdout); err != nil {
+			fatal(err)
+		}
+		if len(drift) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if mode == "outdated" {
+		snaps, err := dkr.ListContainers(ctx, collectOpts.Lister, *includeAll)
+		if err != nil {
+			fatal(err)
+		}
+		if snaps, err = applyWhere(snaps); err != nil {
+			fatal(err)
+		}
+		imageDigests.Apply(ctx, cli, snaps)
+		list := dkr.CheckOutdated(ctx, cli, snaps)
+		if err := ui.RenderOutdated(list, parseOutputFormat(*format), *noTrunc, os.Stdout); err != nil {
+			fatal(err)
+		}
+		for _, o := range list {
+			if o.Stale {
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	// Host details go into JSON rows and, for Docker Desktop, the table title.
+	if host, err := dkr.GetHostInfo(ctx, cli); err == nil {
+		renderOpts.Host = &host
+	} else {
+		slog.Debug("reading host info failed", "err", err)
+	}
+
 	if *watch {
-		if strings.ToLower(*format) == "json" {
-			fmt.Fprintln(os.Stderr, "Error: --watch is not supported with --format=json")
-			os.Exit(2)
+		var cond *untilCond
+		if *until != "" {
+			if cond, err = parseUntil(*until); err != nil {
+				fatal(err)
+			}
+			if err := cond.resolve(ctx, collectOpts.Lister); err != nil {
+				fatal(err)
+			}
+		}
+		if mode == "watch" && len(args) > 0 {
+			// Focus mode: list and sample only the named containers.
+			f, names, err := focusFilters(ctx, cli, collectOpts.Lister, args)
+			if err != nil {
+				fatal(err)
+			}
+			collectOpts.Filters = f
+			slog.Debug("focus mode", "containers", strings.Join(names, ", "))
+		}
+		var session *dkr.SessionIO
+		if *sessionIO {
+			session = dkr.NewSessionIO()
 		}
-		if err := watchContainers(ctx, cli, *includeAll, parseSortKey(*sortKey), *noTrunc, *interval); err != nil {
+		if err := watchContainers(ctx, cli, collectOpts, parseSortKey(*sortKey), renderOpts, *interval, *noClear, cond, session, *sessionOut, *watchFor, *count); err != nil {
 			fatal(err)
 		}
+		exitIfMaxRuntime()
+		if cond != nil {
+			if !cond.met {
+				// Interrupted before the condition held.
+				os.Exit(130)
+			}
+			fmt.Fprintln(os.Stderr, "until:", cond.why)
+			os.Exit(cond.code)
+		}
 		return
 	}
 
 	// One-shot mode
-	snaps, err := dkr.CollectSnapshots(ctx, cli, *includeAll)
+	renderOpts.Sort = parseSortKey(*sortKey)
+	stale := showCached(renderOpts.Sort, renderOpts)
+	collectOpts.Sample = *sample
+	progress := ui.StartProgress(os.Stderr, time.Second)
+	collectOpts.Progress = progress.Update
+	snaps, err := dkr.CollectSnapshots(ctx, collectOpts)
+	progress.Stop()
 	if err != nil {
+		eraseCached(os.Stdout, stale)
+		fatal(err)
+	}
+	debugConcurrency(collectOpts)
+	debugStatsErrors(snaps)
+	restarts := dkr.NewRestartTracker(flapThreshold, flapWindow)
+	if err := restarts.Backfill(ctx, cli); err != nil {
+		slog.Debug("reading restart history failed", "err", err)
+	}
+	restarts.Apply(snaps)
+	if snaps, err = applyWhere(snaps); err != nil {
+		eraseCached(os.Stdout, stale)
 		fatal(err)
 	}
-	ui.SortSnapshots(snaps, parseSortKey(*sortKey))
+	enrich(ctx, cli, snaps)
+	runExporters(ctx, snaps)
+	ui.SortSnapshots(snaps, renderOpts.Sort)
 	of := parseOutputFormat(*format)
-	if err := ui.Render(snaps, of, *noTrunc, os.Stdout); err != nil {
+	renderStart := time.Now()
+	eraseCached(os.Stdout, stale)
+	if err := ui.Render(snaps, of, renderOpts, os.Stdout); err != nil {
 		fatal(err)
 	}
+	reportProfile(collectOpts, time.Since(renderStart))
+	saveCached(snaps)
+}
+
+// flaggedMounts keeps only the flagged mounts of flagged containers.
+func flaggedMounts(list []dkr.ContainerMounts) []dkr.ContainerMounts {
+	var out []dkr.ContainerMounts
+	for _, c := range list {
+		if !c.Flagged() {
+			continue
+		}
+		var flagged []dkr.Mount
+		for _, m := range c.Mounts {
+			if m.Warning != "" {
+				flagged = append(flagged, m)
+			}
+		}
+		c.Mounts = flagged
+		out = append(out, c)
+	}
+	return out
+}
+
+// riskyPorts keeps the database and admin ports bound on all interfaces.
+func riskyPorts(list []dkr.ExposedPort) []dkr.ExposedPort {
+	var out []dkr.ExposedPort
+	for _, p := range list {
+		if p.Risky {
+			out = append(out, p)
+		}
+	}
+	return out
 }
 
 func fatal(err error) {
+	exitIfMaxRuntime()
 	// Normalize and print errors concisely for CLI users.
 	msg := err.Error()
 	msg = strings.TrimSpace(msg)
@@ -104,12 +682,312 @@ func fatal(err error) {
 	os.Exit(1)
 }
 
+// errMaxRuntime cancels the run when --max-runtime (runLimit) runs out;
+// runCtx is the context it cancels.
+var (
+	errMaxRuntime = errors.New("--max-runtime reached")
+	runCtx        context.Context
+	runLimit      time.Duration
+)
+
+// exitIfMaxRuntime exits with status 124, as timeout(1) does, once
+// --max-runtime has stopped the run. Whatever failed because of it (a
+// cancelled API call) is not worth reporting.
+func exitIfMaxRuntime() {
+	if runCtx != nil && errors.Is(context.Cause(runCtx), errMaxRuntime) {
+		fmt.Fprintf(os.Stderr, "Error: stopped after --max-runtime %s\n", runLimit)
+		os.Exit(124)
+	}
+}
+
+// flapThreshold and flapWindow configure restart-loop detection in watch mode.
+var (
+	flapThreshold int
+	flapWindow    time.Duration
+)
+
+// whereExpr is the compiled --where filter, nil when unset.
+var whereExpr *filter.Expr
+
+// applyWhere drops snapshots that don't match --where.
+func applyWhere(snaps []dkr.ContainerSnapshot) ([]dkr.ContainerSnapshot, error) {
+	if whereExpr == nil {
+		return snaps, nil
+	}
+	return whereExpr.Filter(snaps)
+}
+
+// plugins lists column plugin executables from --plugins.
+var plugins []string
+
+// baseline marks readings far from each container's norm (--anomaly-sigma).
+var baseline *dkr.Baseline
+
+// scanner fills the CVES column when --scan is set.
+var scanner *scan.Scanner
+
+// scraper reads --metrics from opted-in containers, nil when unset.
+var scraper *scrape.Scraper
+
+// logErrorWindow and logErrorRe configure the --log-errors column.
+var (
+	logErrorWindow time.Duration
+	logErrorRe     *regexp.Regexp
+)
+
+// lastLog enables the --show-last-log column.
+var lastLog bool
+
+// checkZombies enables the --zombies process check.
+var checkZombies bool
+
+// countFDs enables the --fds column.
+var countFDs bool
+
+// countConns enables the --conns column.
+var countConns bool
+
+// fillHostNet enables --host-net-io.
+var fillHostNet bool
+
+// imageDigests resolves image digests when they are shown or recorded.
+var imageDigests *dkr.ImageDigests
+
+// cpuLimits reads containers' CPU limits for --cpu-scale=limit.
+var cpuLimits *dkr.CPULimits
+
+// enrich adds log, plugin and scraped-metric columns to snaps. Failures are
+// reported on stderr but never abort rendering.
+func enrich(ctx context.Context, cli *client.Client, snaps []dkr.ContainerSnapshot) {
+	if baseline != nil {
+		baseline.Apply(snaps)
+	}
+	if fillHostNet {
+		dkr.FillHostNetwork(snaps)
+	}
+	if logErrorRe != nil {
+		dkr.CountLogMatches(ctx, cli, snaps, logErrorWindow, logErrorRe)
+	}
+	if lastLog {
+		dkr.PopulateLastLog(ctx, cli, snaps)
+	}
+	if checkZombies {
+		dkr.CountZombies(ctx, cli, snaps)
+	}
+	if countFDs {
+		dkr.CountFDs(ctx, cli, snaps)
+	}
+	if countConns {
+		dkr.CountConnections(ctx, cli, snaps)
+	}
+	if imageDigests != nil {
+		imageDigests.Apply(ctx, cli, snaps)
+	}
+	if cpuLimits != nil {
+		cpuLimits.Apply(ctx, cli, snaps)
+	}
+	if err := plugin.Run(ctx, plugins, snaps); err != nil {
+		slog.Warn("plugin failed", "err", err)
+	}
+	if scraper != nil {
+		if err := scraper.Scrape(ctx, snaps); err != nil {
+			slog.Debug("scraping app metrics failed", "err", err)
+		}
+	}
+	if scanner != nil {
+		if err := scanner.Apply(ctx, snaps); err != nil {
+			slog.Debug("scanning images failed", "err", err)
+		}
+	}
+}
+
+// exporters are built from --export and the config file's "exporters"
+// and run after every collection.
+var exporters []export.Exporter
+
+// runExporters hands snaps to each configured exporter. Failures are
+// reported on stderr so one broken sink doesn't stop the others or the view.
+func runExporters(ctx context.Context, snaps []dkr.ContainerSnapshot) {
+	for _, e := range exporters {
+		if err := e.Export(ctx, snaps); err != nil {
+			slog.Warn("export failed", "err", err)
+		}
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ageValue is a duration flag that also accepts whole days ("90d").
+type ageValue time.Duration
+
+func (a *ageValue) String() string { return time.Duration(*a).String() }
+
+func (a *ageValue) Set(s string) error {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid age %q", s)
+		}
+		*a = ageValue(time.Duration(n) * 24 * time.Hour)
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*a = ageValue(d)
+	return nil
+}
+
+// debugConcurrency reports the stats concurrency in effect after a collection.
+func debugConcurrency(opts dkr.CollectOptions) {
+	if opts.Limiter != nil {
+		slog.Debug("stats concurrency", "limit", opts.Limiter.Limit(), "adaptive", true)
+		return
+	}
+	slog.Debug("stats concurrency", "limit", opts.Concurrency, "adaptive", false)
+}
+
+// debugStatsErrors reports, with --debug, why each failed stats read failed.
+func debugStatsErrors(snaps []dkr.ContainerSnapshot) {
+	for _, s := range snaps {
+		if s.StatsError != "" && !s.Gone {
+			slog.Debug("reading stats failed", "container", s.Name, "err", s.StatsError)
+		}
+	}
+}
+
+// reportProfile prints the --profile timing report when timings were collected.
+func reportProfile(opts dkr.CollectOptions, render time.Duration) {
+	if opts.Timings == nil {
+		return
+	}
+	ui.RenderProfile(os.Stderr, *opts.Timings, render, 5)
+}
+
+// envName maps a flag name to its environment variable, e.g. no-trunc -> WHALE_NO_TRUNC.
+func envName(flagName string) string {
+	return "WHALE_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnv fills flags not given on the command line from WHALE_* variables.
+// Precedence is command line, then environment, then config file.
+func applyEnv() error {
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	var err error
+	flag.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		val, ok := os.LookupEnv(envName(f.Name))
+		if !ok || (explicit[f.Name] && !enforced(f.Name, val)) {
+			return
+		}
+		if setErr := flag.Set(f.Name, val); setErr != nil {
+			err = fmt.Errorf("%s: %w", envName(f.Name), setErr)
+		}
+	})
+	return err
+}
+
+// applyConfig fills flags not given on the command line from the config
+// file: first from the selected view, then from the defaults section. It
+// returns the config for the settings that aren't flags.
+func applyConfig(path, view string) (*config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	sets := []map[string]any{}
+	if view != "" {
+		v, err := cfg.View(view)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, v)
+	}
+	sets = append(sets, cfg.Defaults)
+	for _, set := range sets {
+		for name, val := range set {
+			if (explicit[name] && !enforced(name, config.FormatValue(val))) || name == "config" || name == "view" {
+				continue
+			}
+			if flag.Lookup(name) == nil {
+				return nil, fmt.Errorf("config: unknown flag %q", name)
+			}
+			if err := flag.Set(name, config.FormatValue(val)); err != nil {
+				return nil, fmt.Errorf("config: %s: %w", name, err)
+			}
+			explicit[name] = true
+		}
+	}
+	return cfg, nil
+}
+
+// enforced reports whether val for flag name overrides even an explicit
+// command-line value: --read-only turned on by the environment or config
+// file can't be turned off again, so a restricted setup stays restricted.
+func enforced(name, val string) bool {
+	on, _ := strconv.ParseBool(val)
+	return name == "read-only" && on
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments (e.g. `whale grep pattern --since 5m`) and returns the positionals.
+// Everything after a literal "--" is positional and never parsed as flags.
+func parseInterspersed() []string {
+	args, tail := os.Args[1:], []string(nil)
+	for i, a := range args {
+		if a == "--" {
+			args, tail = args[:i], args[i+1:]
+			break
+		}
+	}
+	_ = flag.CommandLine.Parse(args)
+	var positional []string
+	for flag.NArg() > 0 {
+		rest := flag.Args()
+		positional = append(positional, rest[0])
+		_ = flag.CommandLine.Parse(rest[1:])
+	}
+	return append(positional, tail...)
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	found := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
 func parseSortKey(s string) ui.SortKey {
 	switch strings.ToLower(s) {
 	case "mem":
 		return ui.SortMem
 	case "name":
 		return ui.SortName
+	case "cpu-time":
+		return ui.SortCPUTime
+	case "net-rate":
+		return ui.SortNetRate
+	case "disk-rate":
+		return ui.SortBlockRate
 	case "cpu":
 		fallthrough
 	default:
@@ -117,61 +995,269 @@ func parseSortKey(s string) ui.SortKey {
 	}
 }
 
+// checkViewFormat rejects formats the chosen view can't write. Only the
+// one-shot container list renders through the format registry; the other
+// views know table and JSON, and watch mode draws a table.
+func checkViewFormat(mode string, watch bool, f ui.OutputFormat) error {
+	name, _, _ := strings.Cut(string(f), ":")
+	switch {
+	case f == ui.FormatTable, mode == "" && !watch:
+		return nil
+	case watch:
+		return fmt.Errorf("--watch is not supported with --format=%s", name)
+	case f == ui.FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("--format=%s is not supported by whale %s (want table or json)", name, mode)
+}
+
 func parseOutputFormat(s string) ui.OutputFormat {
-	switch strings.ToLower(s) {
-	case "json":
-		return ui.FormatJSON
-	case "table":
-		fallthrough
-	default:
-		return ui.FormatTable
+	// Only the name is case-insensitive; a template argument is kept as is.
+	name, arg, hasArg := strings.Cut(s, ":")
+	name = strings.ToLower(name)
+	if hasArg {
+		return ui.OutputFormat(name + ":" + arg)
 	}
+	return ui.OutputFormat(name)
 }
 
-// watchContainers continuously refreshes and renders the container table.
-func watchContainers(parent context.Context, cli *client.Client, includeAll bool, sortKey ui.SortKey, noTrunc bool, interval time.Duration) error {
-	// Use a non-timed context so the loop runs until Ctrl+C.
-	ctx := context.Background()
+// watchContainers refreshes the container table every interval until ctx
+// is cancelled, q is pressed, duration or count refreshes (if non-zero) have
+// passed or, when until is set, its condition is met, then prints a summary of the session
+// (and saves it to sessionOut, if set). With session set, I/O columns show
+// totals since its baseline; pressing r resets the baseline (starting a
+// session if there was none). Lists taller than the terminal are paged with
+// PgUp/PgDn (or b/space). With --mouse, a click selects a row (a second
+// click opens its action menu), a click on a column header sorts by it and
+// the wheel pages.
+func watchContainers(ctx context.Context, cli *client.Client, opts dkr.CollectOptions, sortKey ui.SortKey, renderOpts ui.RenderOptions, interval time.Duration, noClear bool, until *untilCond, session *dkr.SessionIO, sessionOut string, duration time.Duration, count int) error {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
-	for {
+	var expired <-chan time.Time
+	if duration > 0 {
+		expired = time.After(duration)
+	}
+	lastKnown := dkr.LastKnown{}
+	restarts := dkr.NewRestartTracker(flapThreshold, flapWindow)
+	go restarts.Run(ctx, cli)
+	pidTrend := dkr.NewPIDTrend(5)
+	memTrend := dkr.NewMemTrend(memTrendWindow(interval))
+	arrivals := dkr.NewArrivals(3)
+	departures := dkr.NewDepartures(3)
+	rates := dkr.NewIORates()
+	recorder := dkr.NewRecorder()
+	recorder.Series = sessionOut != ""
+	finish := func() error {
+		sum := recorder.Summary()
+		ui.RenderSessionSummary(os.Stdout, sum)
+		if sessionOut == "" {
+			return nil
+		}
+		return writeSession(sessionOut, sum)
+	}
+	if sortKey == ui.SortNetRate || sortKey == ui.SortBlockRate {
+		// Show what the table is ranked by.
+		renderOpts.ShowRates = true
+	}
+	renderOpts.Sort = sortKey
+	keys, restoreTerm := readKeys()
+	// An exec from the action menu replaces the key reader.
+	defer func() { restoreTerm() }()
+	var sel watchSelection
+	var screen screenMap
+	page := 0
+	if !noClear {
+		// Something to read while the first collection runs.
+		refreshScreen(noClear)
+		showCached(sortKey, renderOpts)
+	}
+	for n := 1; ; n++ {
 		// Collect and render
-		snaps, err := dkr.CollectSnapshots(ctx, cli, includeAll)
+		opts.Lister.Next()
+		snaps, err := dkr.CollectSnapshots(ctx, opts)
 		if err != nil {
+			if ctx.Err() != nil {
+				return finish() // interrupted mid-collection
+			}
+			return err
+		}
+		lastKnown.Apply(snaps)
+		restarts.Apply(snaps)
+		pidTrend.Apply(snaps)
+		memTrend.Apply(snaps)
+		arrivals.Apply(snaps)
+		snaps = departures.Apply(ctx, cli, snaps)
+		rates.Apply(snaps)
+		debugConcurrency(opts)
+		debugStatsErrors(snaps)
+		if snaps, err = applyWhere(snaps); err != nil {
 			return err
 		}
+		enrich(ctx, cli, snaps)
+		runExporters(ctx, snaps)
+		recorder.Apply(snaps)
+		if session != nil {
+			// Display only: exporters above get the raw counters.
+			session.Apply(snaps)
+			renderOpts.IOSince = session.Since()
+		}
 		ui.SortSnapshots(snaps, sortKey)
-		ui.ClearScreen(os.Stdout)
-		_ = ui.Render(snaps, ui.FormatTable, noTrunc, os.Stdout)
+		saveCached(snaps)
+		shown := snaps
+		renderOpts.Page = ui.Page{}
+		if perPage := ui.RowsPerScreen(renderOpts.Layout, os.Stdout); !noClear && perPage > 0 && len(snaps) > perPage {
+			pages := (len(snaps) + perPage - 1) / perPage
+			page = min(page, pages-1)
+			first := page * perPage
+			shown = snaps[first:min(first+perPage, len(snaps))]
+			renderOpts.Page = ui.Page{First: first, Total: len(snaps)}
+		} else {
+			page = 0
+		}
+		refreshScreen(noClear)
+		renderStart := time.Now()
+		renderOpts.Selected = sel.id
+		// With the mouse on, the frame is kept to map clicks back to it.
+		var out io.Writer = os.Stdout
+		frame := &frameRecorder{}
+		if mouseEnabled && !noClear {
+			out = frame
+		}
+		_ = ui.Render(shown, ui.FormatTable, renderOpts, out)
+		ui.RenderNotices(out, restarts.Notices())
+		sel.render(out, shown)
+		if out == frame {
+			screen = newScreenMap(frame.String(), shown)
+			_, _ = frame.WriteTo(os.Stdout)
+		}
+		reportProfile(opts, time.Since(renderStart))
+		if until != nil {
+			if err := until.check(ctx, cli, snaps, time.Now()); err != nil {
+				return err
+			}
+			if until.met {
+				return finish()
+			}
+		}
+		if n == count {
+			return finish()
+		}
 
 		select {
 		case <-ticker.C:
-			continue
-		case <-parent.Done():
-			return nil
+		case k := <-keys:
+			var handled bool
+			if keys, restoreTerm, handled = sel.handleKey(ctx, cli, opts.Lister, os.Stdout, k, shown, keys, restoreTerm); handled {
+				ticker.Reset(interval)
+				continue
+			}
+			switch k {
+			case "r", "R":
+				if session == nil {
+					session = dkr.NewSessionIO()
+				} else {
+					session.Reset()
+				}
+			case "pgdn", " ", "wheeldown":
+				page++ // clamped to the last page on render
+			case "pgup", "b", "wheelup":
+				page = max(page-1, 0)
+			case "q", "Q":
+				return finish()
+			default:
+				x, y, ok := parseClick(k)
+				if !ok {
+					break
+				}
+				if id, ok := screen.rowAt(y); ok {
+					// A click on the selected row opens its menu.
+					sel.menu = id == sel.id
+					sel.id = id
+				} else if header, ok := screen.headerAt(x, y); ok {
+					if key, ok := ui.HeaderSortKey(header); ok {
+						sortKey = key
+						renderOpts.Sort = key
+						renderOpts.ShowRates = renderOpts.ShowRates || key == ui.SortNetRate || key == ui.SortBlockRate
+					}
+				}
+			}
+			// Redraw right away so the key press has visible effect.
+			ticker.Reset(interval)
+		case <-expired:
+			return finish()
+		case <-ctx.Done():
+			return finish()
 		}
 	}
 }
 
-// watchNetworks continuously refreshes and renders the networks table.
-func watchNetworks(parent context.Context, cli *client.Client, includeAll bool, noTrunc bool, interval time.Duration) error {
-	ctx := context.Background()
+// memTrendWindow is how much history the memory-growth estimate fits: at
+// least five minutes, so short bursts (a GC cycle, a request spike) do not
+// read as leaks, and at least ten refreshes at slow intervals.
+func memTrendWindow(interval time.Duration) time.Duration {
+	return max(5*time.Minute, 10*interval)
+}
+
+// watchNetworks continuously refreshes and renders the networks table, or
+// count times when count is non-zero, with recent connects and disconnects
+// listed below it.
+func watchNetworks(ctx context.Context, cli *client.Client, opts dkr.CollectOptions, includeAll bool, noTrunc bool, interval time.Duration, noClear bool, count int) error {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
-	for {
-		groups, err := dkr.CollectNetworks(ctx, cli, includeAll)
+	var membership dkr.MembershipLog
+	for n := 1; ; n++ {
+		opts.Lister.Next()
+		groups, err := opts.Collector.Networks(ctx, includeAll)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil // interrupted mid-collection
+			}
 			return err
 		}
-		ui.ClearScreen(os.Stdout)
-		if err := ui.RenderNetworks(groups, noTrunc, os.Stdout); err != nil {
+		addSwarmServices(ctx, cli, groups)
+		membership.Apply(groups)
+		refreshScreen(noClear)
+		if err := ui.RenderNetworks(groups, networkParents(ctx, cli), noTrunc, os.Stdout); err != nil {
 			return err
 		}
+		ui.RenderMembershipChanges(os.Stdout, membership.Changes())
+		if n == count {
+			return nil
+		}
 		select {
 		case <-ticker.C:
 			continue
-		case <-parent.Done():
+		case <-ctx.Done():
 			return nil
 		}
 	}
 }
+
+// addSwarmServices lists Swarm services in the network view. Like the
+// macvlan notes it is best effort.
+func addSwarmServices(ctx context.Context, cli *client.Client, groups map[string][]dkr.ContainerNetInfo) {
+	if err := dkr.AddSwarmServices(ctx, cli, groups); err != nil {
+		slog.Debug("listing swarm services failed", "err", err)
+	}
+}
+
+// networkParents returns the macvlan/ipvlan notes for the network view. They
+// are cosmetic, so a failure only drops them.
+func networkParents(ctx context.Context, cli *client.Client) map[string]string {
+	notes, err := dkr.NetworkParents(ctx, cli)
+	if err != nil {
+		slog.Debug("reading network parents failed", "err", err)
+	}
+	return notes
+}
+
+// refreshScreen prepares stdout for the next watch frame. With noClear the
+// previous frames are kept and a timestamped delimiter is printed instead, so
+// the output can be piped to a file or tee as an audit log.
+func refreshScreen(noClear bool) {
+	if noClear {
+		ui.PrintDelimiter(os.Stdout, time.Now())
+		return
+	}
+	ui.ClearScreen(os.Stdout)
+}
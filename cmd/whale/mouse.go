@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// mouseEnabled turns on mouse reporting in readKeys (--mouse). It is opt-in
+// because while it is on the terminal no longer selects text on drag
+// (most terminals still do with Shift held).
+var mouseEnabled bool
+
+// Mouse reporting: button presses (1000) in SGR encoding (1006), which has
+// no coordinate limit.
+const (
+	mouseOn  = "\x1b[?1000h\x1b[?1006h"
+	mouseOff = "\x1b[?1000l\x1b[?1006l"
+)
+
+// parseMouse turns the SGR mouse reports in one read ("\x1b[<b;x;yM", one or
+// more) into keys: "click X Y" for the left button (1-based cell
+// coordinates) and "wheelup"/"wheeldown". Releases and other buttons are
+// dropped.
+func parseMouse(s string) []string {
+	var keys []string
+	for _, ev := range strings.Split(s, "\x1b[<")[1:] {
+		if !strings.HasSuffix(ev, "M") {
+			continue // release ("m") or garbage
+		}
+		f := strings.Split(strings.TrimSuffix(ev, "M"), ";")
+		if len(f) != 3 {
+			continue
+		}
+		b, err1 := strconv.Atoi(f[0])
+		x, err2 := strconv.Atoi(f[1])
+		y, err3 := strconv.Atoi(f[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		switch b {
+		case 0:
+			keys = append(keys, fmt.Sprintf("click %d %d", x, y))
+		case 64:
+			keys = append(keys, "wheelup")
+		case 65:
+			keys = append(keys, "wheeldown")
+		}
+	}
+	return keys
+}
+
+// parseClick reads the coordinates of a "click X Y" key.
+func parseClick(k string) (x, y int, ok bool) {
+	_, err := fmt.Sscanf(k, "click %d %d", &x, &y)
+	return x, y, err == nil
+}
+
+// frameRecorder buffers a watch frame bound for stdout, so it can be mapped
+// for mouse clicks before it is written. It reports stdout's width so the
+// frame is laid out as if rendered there directly.
+type frameRecorder struct {
+	bytes.Buffer
+}
+
+func (f *frameRecorder) TerminalWidth() int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		return w
+	}
+	return 0
+}
+
+// screenMap locates the containers and column headers of a rendered frame,
+// to map mouse clicks back to them.
+type screenMap struct {
+	lines  []string       // frame lines without color codes
+	rows   map[int]string // line index -> container ID
+	header int            // line index of the table header, -1 if none
+}
+
+// newScreenMap maps frame, which was drawn from the top of a cleared screen.
+// A table row or card spans the lines between two separators (or blank
+// lines) and belongs to the container whose short ID appears in it.
+func newScreenMap(frame string, shown []dkr.ContainerSnapshot) screenMap {
+	m := screenMap{rows: map[int]string{}, header: -1}
+	m.lines = strings.Split(text.StripEscape(frame), "\n")
+	start := 0
+	flush := func(end int) {
+		id := ""
+		for i := start; i < end && id == ""; i++ {
+			for _, s := range shown {
+				if strings.Contains(m.lines[i], ui.TruncateID(s.ID, false)) {
+					id = s.ID
+					break
+				}
+			}
+		}
+		for i := start; i < end && id != ""; i++ {
+			m.rows[i] = id
+		}
+	}
+	for i, line := range m.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "├") || strings.HasPrefix(trimmed, "╭") || strings.HasPrefix(trimmed, "╰") {
+			flush(i)
+			start = i + 1
+			continue
+		}
+		if m.header < 0 && strings.Contains(line, "│ NAME") {
+			m.header = i
+		}
+	}
+	flush(len(m.lines))
+	return m
+}
+
+// rowAt returns the container drawn at screen line y (1-based).
+func (m screenMap) rowAt(y int) (string, bool) {
+	id, ok := m.rows[y-1]
+	return id, ok
+}
+
+// headerAt returns the column header at screen cell x, y (1-based), if y is
+// the table's header line.
+func (m screenMap) headerAt(x, y int) (string, bool) {
+	if m.header < 0 || y-1 != m.header {
+		return "", false
+	}
+	cells := strings.Split(m.lines[m.header], "│")
+	col := 0
+	for _, c := range cells {
+		col += len([]rune(c)) + 1 // the cell and the separator after it
+		if x <= col {
+			return strings.TrimSpace(c), true
+		}
+	}
+	return "", false
+}
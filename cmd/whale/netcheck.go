@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// runNetCheck reports which networks the containers queryA and queryB share
+// and, with probe (or a port), whether A can actually reach B on each of
+// them. It returns false when they share no network or a probe failed.
+func runNetCheck(ctx context.Context, cli *client.Client, lister *dkr.Lister, queryA, queryB string, probe bool, port int) (bool, error) {
+	snaps, err := dkr.ListContainers(ctx, lister, false)
+	if err != nil {
+		return false, err
+	}
+	a, err := resolveContainer(snaps, queryA)
+	if err != nil {
+		return false, err
+	}
+	b, err := resolveContainer(snaps, queryB)
+	if err != nil {
+		return false, err
+	}
+	shared := dkr.SharedNetworks(a, b)
+	if len(shared) == 0 {
+		fmt.Printf("%s and %s share no network.\n", a.Name, b.Name)
+		fmt.Printf("  %s is on: %s\n", a.Name, networkNames(a))
+		fmt.Printf("  %s is on: %s\n", b.Name, networkNames(b))
+		if len(b.Networks) > 0 && b.Networks[0].Name != network.NetworkHost {
+			fmt.Printf("Connect them with e.g.: docker network connect %s %s\n", b.Networks[0].Name, a.Name)
+		}
+		return false, nil
+	}
+	names := make([]string, len(shared))
+	for i, n := range shared {
+		names[i] = n.Name
+	}
+	fmt.Printf("%s and %s share %d network(s): %s\n", a.Name, b.Name, len(shared), strings.Join(names, ", "))
+	ok := true
+	for _, n := range shared {
+		fmt.Printf("  %-20s %s %s  →  %s %s\n", n.Name, a.Name, orDash(n.IPA), b.Name, orDash(n.IPB))
+		if !probe && port == 0 {
+			continue
+		}
+		target := n.IPB
+		if n.Name == network.NetworkHost {
+			target = "127.0.0.1"
+		}
+		if target == "" {
+			fmt.Printf("    probe skipped: %s has no address on %s\n", b.Name, n.Name)
+			continue
+		}
+		res, err := dkr.Probe(ctx, cli, a.ID, target, port)
+		if err != nil {
+			fmt.Printf("    probe: %v\n", err)
+			ok = false
+			continue
+		}
+		verdict := text.Colors{text.FgGreen}.Sprint("reachable")
+		if !res.OK {
+			verdict = text.Colors{text.FgHiRed}.Sprint("unreachable")
+			ok = false
+		}
+		fmt.Printf("    %s  (%s)\n", verdict, res.Method)
+		if !res.OK && res.Output != "" {
+			fmt.Printf("    %s\n", lastLine(res.Output))
+		}
+	}
+	return ok, nil
+}
+
+// networkNames lists a container's networks for messages.
+func networkNames(s dkr.ContainerSnapshot) string {
+	if len(s.Networks) == 0 {
+		return "(none)"
+	}
+	names := make([]string, len(s.Networks))
+	for i, n := range s.Networks {
+		names[i] = n.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// embeddedDNS is the resolver Docker injects on user-defined networks.
+const embeddedDNS = "127.0.0.11"
+
+// runNetResolve looks name up from inside the container query and prints
+// the addresses it resolves to, naming the containers that own them. It
+// returns false when the name does not resolve.
+func runNetResolve(ctx context.Context, cli *client.Client, lister *dkr.Lister, query, name string) (bool, error) {
+	snaps, err := dkr.ListContainers(ctx, lister, false)
+	if err != nil {
+		return false, err
+	}
+	from, err := resolveContainer(snaps, query)
+	if err != nil {
+		return false, err
+	}
+	l, err := dkr.LookupIn(ctx, cli, from.ID, name)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", from.Name, err)
+	}
+	resolver := strings.Join(l.Resolvers, ", ")
+	switch {
+	case resolver == "":
+		resolver = "unknown resolver"
+	case len(l.Resolvers) == 1 && l.Resolvers[0] == embeddedDNS:
+		resolver += ", Docker's embedded DNS"
+	}
+	fmt.Printf("%s from %s (%s) via %s:\n", name, from.Name, resolver, l.Method)
+	if len(l.Addresses) == 0 {
+		fmt.Println(text.Colors{text.FgHiRed}.Sprint("  does not resolve"))
+		if l.Output != "" {
+			fmt.Printf("  %s\n", lastLine(l.Output))
+		}
+		// The usual compose mistake: the target runs, just not on a shared network.
+		for _, s := range snaps {
+			if strings.EqualFold(s.Name, name) || strings.EqualFold(s.Labels[dkr.ComposeServiceLabel], name) {
+				if len(dkr.SharedNetworks(from, s)) == 0 {
+					fmt.Printf("  container %s exists but shares no network with %s (it is on: %s)\n", s.Name, from.Name, networkNames(s))
+				}
+			}
+		}
+		return false, nil
+	}
+	owners := map[string]string{}
+	for _, s := range snaps {
+		for _, n := range s.Networks {
+			if n.IP != "" {
+				owners[n.IP] = fmt.Sprintf("%s (%s)", s.Name, n.Name)
+			}
+			if n.IPv6 != "" {
+				owners[n.IPv6] = fmt.Sprintf("%s (%s)", s.Name, n.Name)
+			}
+		}
+	}
+	for _, a := range l.Addresses {
+		fmt.Printf("  %-40s %s\n", a, owners[a])
+	}
+	return true, nil
+}
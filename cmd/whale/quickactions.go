@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// quickAction is one entry of the watch view's per-container menu, opened
+// with Enter on the selected row.
+type quickAction struct {
+	key   string
+	label string
+	// mutates marks actions disabled by --read-only and recorded in the
+	// audit log.
+	mutates bool
+}
+
+var quickActionMenu = []quickAction{
+	{key: "l", label: "logs"},
+	{key: "e", label: "exec", mutates: true},
+	{key: "i", label: "inspect"},
+	{key: "s", label: "stop", mutates: true},
+	{key: "r", label: "restart", mutates: true},
+	{key: "c", label: "copy ID"},
+	{key: "n", label: "copy name"},
+	{key: "o", label: "open port"},
+}
+
+// quickActionConfig is what the menu's actions need from the command line.
+type quickActionConfig struct {
+	audit    auditConfig
+	readOnly bool
+}
+
+// quickActions is set from --audit-log, --reason and --read-only.
+var quickActions quickActionConfig
+
+// renderQuickActions prints the action menu for s below the watch table.
+func renderQuickActions(w io.Writer, s dkr.ContainerSnapshot) {
+	items := make([]string, 0, len(quickActionMenu)+1)
+	for _, a := range quickActionMenu {
+		item := text.Colors{text.FgCyan, text.Bold}.Sprintf("[%s]", a.key) + " " + a.label
+		if a.mutates && quickActions.readOnly {
+			item = text.Colors{text.Faint}.Sprintf("[%s] %s", a.key, a.label)
+		}
+		items = append(items, item)
+	}
+	items = append(items, text.Colors{text.Faint}.Sprint("Esc close"))
+	fmt.Fprintf(w, "%s  %s\n", text.Colors{text.Bold}.Sprint(s.Name), strings.Join(items, "  "))
+}
+
+// runQuickAction performs the menu action bound to key on s, except exec
+// (see execFromWatch), and returns a one-line result for the watch view.
+// The log pane takes over the screen until it is closed, inspect until a
+// key is pressed. ok is false when key is not a menu action.
+func runQuickAction(ctx context.Context, cli *client.Client, w io.Writer, key string, s dkr.ContainerSnapshot, keys <-chan string) (msg string, ok bool) {
+	var act quickAction
+	for _, a := range quickActionMenu {
+		if a.key == key {
+			act, ok = a, true
+		}
+	}
+	if !ok {
+		return "", false
+	}
+	if act.mutates && quickActions.readOnly {
+		return fmt.Sprintf("%s is disabled in read-only mode", act.label), true
+	}
+	switch act.label {
+	case "logs":
+		return runLogPane(ctx, cli, w, s, keys), true
+	case "inspect":
+		return pageOutput(ctx, w, keys, func(w io.Writer) error { return writeInspect(ctx, cli, s, w) }), true
+	case "stop", "restart":
+		fmt.Fprintf(w, "%s %s? [y/N] ", strings.ToUpper(act.label[:1])+act.label[1:], s.Name)
+		select {
+		case <-ctx.Done():
+			return "", true
+		case k := <-keys:
+			if k != "y" && k != "Y" {
+				return "", true
+			}
+		}
+		if err := runLifecycle(ctx, cli, lifecycleActions[act.label], s); err != nil {
+			return fmt.Sprintf("%s %s: %v", act.label, s.Name, err), true
+		}
+		return fmt.Sprintf("%s %s", lifecycleActions[act.label].past, s.Name), true
+	case "copy ID":
+		return copyToClipboard(w, s.ID), true
+	case "copy name":
+		return copyToClipboard(w, s.Name), true
+	case "open port":
+		url, ok := publishedURL(s.Ports)
+		if !ok {
+			return s.Name + " publishes no TCP port", true
+		}
+		if err := openBrowser(url); err != nil {
+			return fmt.Sprintf("open %s: %v", url, err), true
+		}
+		return "opened " + url, true
+	}
+	return "", true
+}
+
+// copyToClipboard sets the clipboard to v and returns the result line.
+// OSC 52 asks the terminal to set the clipboard; it also works over SSH.
+// Terminals without it ignore the sequence, so the result shows v too.
+func copyToClipboard(w io.Writer, v string) string {
+	fmt.Fprintf(w, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(v)))
+	return "copied " + v
+}
+
+// publishedURL returns the URL of the first published TCP port in ports
+// (formatted like `docker ps`, "0.0.0.0:8080->80/tcp"), preferring one that
+// maps to container port 443 over https. Ports published on all interfaces
+// are opened on localhost.
+func publishedURL(ports []string) (string, bool) {
+	var url string
+	for _, p := range ports {
+		host, mapping, ok := strings.Cut(p, "->")
+		if !ok || !strings.HasSuffix(mapping, "/tcp") {
+			continue
+		}
+		i := strings.LastIndex(host, ":")
+		ip, port := host[:i], host[i+1:]
+		switch ip {
+		case "0.0.0.0", "::", "127.0.0.1", "::1":
+			ip = "localhost"
+		default:
+			if strings.Contains(ip, ":") {
+				ip = "[" + ip + "]"
+			}
+		}
+		if mapping == "443/tcp" {
+			return "https://" + ip + ":" + port, true
+		}
+		if url == "" {
+			url = "http://" + ip + ":" + port
+		}
+	}
+	return url, url != ""
+}
+
+// openBrowser opens url in the desktop's default browser without waiting
+// for it.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// runLifecycle applies act to a single container and records it in the
+// audit log, like runBulk does for a filtered set.
+func runLifecycle(ctx context.Context, cli *client.Client, act lifecycleAction, s dkr.ContainerSnapshot) error {
+	log, err := quickActions.audit.open()
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+	err = act.run(ctx, cli, s.ID)
+	if logErr := log.Record(act.name, s.Name, s.ID, "", err); logErr != nil {
+		slog.Warn("writing the audit log failed", "err", logErr)
+	}
+	return err
+}
+
+// execFromWatch hands the terminal to an interactive shell in s and takes
+// it back afterwards: restoreTerm stops the watch view's key reader, and
+// the returned channel and func replace the ones it belonged to.
+func execFromWatch(ctx context.Context, cli *client.Client, lister *dkr.Lister, s dkr.ContainerSnapshot, restoreTerm func()) (<-chan string, func(), string) {
+	if quickActions.readOnly {
+		return nil, nil, "exec is disabled in read-only mode"
+	}
+	restoreTerm()
+	ui.ClearScreen(os.Stdout)
+	code, err := runExec(ctx, cli, lister, s.ID, nil, false, quickActions.audit)
+	keys, restore := readKeys()
+	if err != nil {
+		return keys, restore, fmt.Sprintf("exec %s: %v", s.Name, err)
+	}
+	return keys, restore, fmt.Sprintf("exec in %s exited (%d)", s.Name, code)
+}
+
+// pageOutput clears w, shows what write produces and waits for a key press
+// (or ctx to be done) before the watch view is redrawn. A write error
+// becomes the result line.
+func pageOutput(ctx context.Context, w io.Writer, keys <-chan string, write func(io.Writer) error) string {
+	var buf bytes.Buffer
+	err := write(&buf)
+	if err != nil {
+		return err.Error()
+	}
+	ui.ClearScreen(w)
+	_, _ = buf.WriteTo(w)
+	fmt.Fprint(w, text.Colors{text.Faint}.Sprint("\n-- press any key to return --"))
+	select {
+	case <-ctx.Done():
+	case <-keys:
+	}
+	return ""
+}
+
+// writeInspect writes s's `docker inspect` JSON.
+func writeInspect(ctx context.Context, cli *client.Client, s dkr.ContainerSnapshot, w io.Writer) error {
+	cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_, raw, err := cli.ContainerInspectWithRaw(cctx, s.ID, false)
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", s.Name, err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err = out.WriteTo(w)
+	return err
+}
+
+// watchSelection is the watch view's selected row and menu state. The row
+// is tracked by container ID, so it follows the container when the sort
+// order changes.
+type watchSelection struct {
+	id   string
+	menu bool
+	msg  string // result of the last action
+}
+
+// current returns the selected container if it is on screen.
+func (ws *watchSelection) current(shown []dkr.ContainerSnapshot) (dkr.ContainerSnapshot, bool) {
+	for _, s := range shown {
+		if s.ID == ws.id {
+			return s, true
+		}
+	}
+	return dkr.ContainerSnapshot{}, false
+}
+
+// move selects the row d rows from the current one, or the first row when
+// none is selected.
+func (ws *watchSelection) move(shown []dkr.ContainerSnapshot, d int) {
+	if len(shown) == 0 {
+		return
+	}
+	i := -1
+	for j, s := range shown {
+		if s.ID == ws.id {
+			i = j
+		}
+	}
+	if i < 0 {
+		ws.id = shown[0].ID
+		return
+	}
+	ws.id = shown[min(max(i+d, 0), len(shown)-1)].ID
+}
+
+// render prints the menu and the last action's result below the table.
+func (ws *watchSelection) render(w io.Writer, shown []dkr.ContainerSnapshot) {
+	if s, ok := ws.current(shown); ok && ws.menu {
+		renderQuickActions(w, s)
+	}
+	if ws.msg != "" {
+		fmt.Fprintln(w, text.Colors{text.Faint}.Sprint(ws.msg))
+	}
+}
+
+// handleKey applies a key press in the watch view, whose output goes to w.
+// Keys it doesn't use return false for the caller's own bindings. An exec
+// hands the terminal over, so the key channel and restore func may be
+// replaced.
+func (ws *watchSelection) handleKey(ctx context.Context, cli *client.Client, lister *dkr.Lister, w io.Writer, k string, shown []dkr.ContainerSnapshot, keys <-chan string, restoreTerm func()) (<-chan string, func(), bool) {
+	ws.msg = ""
+	if ws.menu {
+		ws.menu = false
+		s, ok := ws.current(shown)
+		switch {
+		case !ok, k == "\x1b", k == "q", k == "Q":
+		case k == "e":
+			if newKeys, restore, msg := execFromWatch(ctx, cli, lister, s, restoreTerm); restore != nil {
+				keys, restoreTerm, ws.msg = newKeys, restore, msg
+			} else {
+				ws.msg = msg
+			}
+		default:
+			var known bool
+			if ws.msg, known = runQuickAction(ctx, cli, w, k, s, keys); !known {
+				ws.menu = true
+			}
+		}
+		return keys, restoreTerm, true
+	}
+	switch k {
+	case "up", "k":
+		ws.move(shown, -1)
+	case "down", "j":
+		ws.move(shown, 1)
+	case "\n":
+		if _, ok := ws.current(shown); !ok {
+			ws.move(shown, 0)
+		}
+		ws.menu = ws.id != ""
+	case "\x1b":
+		ws.id = ""
+	default:
+		return keys, restoreTerm, false
+	}
+	return keys, restoreTerm, true
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"golang.org/x/term"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// resolveContainer picks the container query refers to (see dkr.Resolve):
+// an exact or partial name, an ID prefix, or a fuzzy match. When several
+// containers match equally well and whale runs on a terminal, it asks which
+// one was meant; otherwise the ambiguity is an error listing them.
+func resolveContainer(snaps []dkr.ContainerSnapshot, query string) (dkr.ContainerSnapshot, error) {
+	s, err := dkr.Resolve(snaps, query)
+	var amb *dkr.AmbiguousError
+	if !errors.As(err, &amb) || !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return s, err
+	}
+	return pickContainer(os.Stdin, os.Stdout, amb)
+}
+
+// pickContainer lists the candidates of an ambiguous query and reads the
+// number of the one to use. An empty or invalid answer cancels.
+func pickContainer(in io.Reader, out io.Writer, amb *dkr.AmbiguousError) (dkr.ContainerSnapshot, error) {
+	fmt.Fprintf(out, "%q matches %d containers:\n", amb.Query, len(amb.Matches))
+	for i, m := range amb.Matches {
+		fmt.Fprintf(out, "  %2d) %-30s %s  %s\n", i+1, m.Name, ui.TruncateID(m.ID, false), m.Status)
+	}
+	fmt.Fprintf(out, "Which one? [1-%d] ", len(amb.Matches))
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return dkr.ContainerSnapshot{}, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(amb.Matches) {
+		return dkr.ContainerSnapshot{}, amb
+	}
+	return amb.Matches[n-1], nil
+}
+
+// focusFilters resolves the containers named on `whale watch` among all
+// containers, running or not, and returns a filter listing only them.
+func focusFilters(ctx context.Context, cli *client.Client, lister *dkr.Lister, queries []string) (filters.Args, []string, error) {
+	snaps, err := dkr.ListContainers(ctx, lister, true)
+	if err != nil {
+		return filters.Args{}, nil, err
+	}
+	names := make([]string, len(queries))
+	for i, q := range queries {
+		s, err := resolveContainer(snaps, q)
+		if err != nil {
+			return filters.Args{}, nil, err
+		}
+		names[i] = s.Name
+	}
+	return dkr.NameFilters(names), names, nil
+}
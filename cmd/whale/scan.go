@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/docker/docker/client"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/scan"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// runScan shows the findings for query's image, or with no query a
+// per-image overview of every running container.
+func runScan(ctx context.Context, cli *client.Client, lister *dkr.Lister, sc *scan.Scanner, query string, format ui.OutputFormat, noTrunc bool) error {
+	snaps, err := dkr.ListContainers(ctx, lister, false)
+	if err != nil {
+		return err
+	}
+	if query != "" {
+		target, err := resolveContainer(snaps, query)
+		if err != nil {
+			return err
+		}
+		if target.ImageID == "" {
+			return errors.New("container has no image ID")
+		}
+		r, err := sc.Scan(ctx, target.Image, target.ImageID)
+		if err != nil {
+			return err
+		}
+		return ui.RenderScan(r, format, noTrunc, os.Stdout)
+	}
+	if snaps, err = applyWhere(snaps); err != nil {
+		return err
+	}
+	byImage := map[string]*ui.ScanRow{}
+	var order []string
+	for _, s := range snaps {
+		if s.ImageID == "" {
+			continue
+		}
+		if byImage[s.ImageID] == nil {
+			byImage[s.ImageID] = &ui.ScanRow{Image: s.Image}
+			order = append(order, s.ImageID)
+		}
+		byImage[s.ImageID].Containers = append(byImage[s.ImageID].Containers, s.Name)
+	}
+	rows := make([]ui.ScanRow, 0, len(order))
+	for _, id := range order {
+		row := byImage[id]
+		if r, err := sc.Scan(ctx, row.Image, id); err != nil {
+			row.Error = err.Error()
+		} else {
+			row.Result = r
+		}
+		rows = append(rows, *row)
+	}
+	// Worst first: most critical, then most high, and so on.
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].Result, rows[j].Result
+		if a == nil || b == nil {
+			return a != nil
+		}
+		for _, sev := range scan.Severities {
+			if a.Counts[sev] != b.Counts[sev] {
+				return a.Counts[sev] > b.Counts[sev]
+			}
+		}
+		return false
+	})
+	return ui.RenderScanSummary(rows, format, noTrunc, os.Stdout)
+}
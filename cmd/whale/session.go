@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// writeSession saves a watch session, including its per-refresh time series,
+// to path as one JSON document for charting later.
+func writeSession(path string, sum dkr.SessionSummary) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("session-out: %w", err)
+	}
+	if err := json.NewEncoder(f).Encode(sum); err != nil {
+		f.Close()
+		return fmt.Errorf("session-out: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("session-out: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "saved session (%d refreshes) to %s\n", sum.Refreshes, path)
+	return nil
+}
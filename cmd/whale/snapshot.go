@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/docker/client"
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/store"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// runSnapshot collects one snapshot and appends it, labeled with tag, to the
+// snapshot store so cron/CI jobs can record named points in time.
+func runSnapshot(ctx context.Context, cli *client.Client, opts dkr.CollectOptions, tag, path string) error {
+	if tag == "" {
+		return errors.New("snapshot requires --tag")
+	}
+	snaps, err := dkr.CollectSnapshots(ctx, opts)
+	if err != nil {
+		return err
+	}
+	debugConcurrency(opts)
+	debugStatsErrors(snaps)
+	if snaps, err = applyWhere(snaps); err != nil {
+		return err
+	}
+	enrich(ctx, cli, snaps)
+	rec := store.Snapshot{Tag: tag, Time: time.Now().UTC(), Containers: snaps}
+	if err := store.Append(path, rec); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "saved snapshot %q (%d containers) to %s\n", tag, len(snaps), path)
+	return nil
+}
+
+// runDiff compares the latest snapshots tagged from and to in the store.
+func runDiff(from, to, path string, format ui.OutputFormat, noTrunc bool) error {
+	all, err := store.Load(path)
+	if err != nil {
+		return err
+	}
+	a, err := store.Find(all, from)
+	if err != nil {
+		return err
+	}
+	b, err := store.Find(all, to)
+	if err != nil {
+		return err
+	}
+	return ui.RenderDiff(a, b, store.Diff(a, b), format, noTrunc, os.Stdout)
+}
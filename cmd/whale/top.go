@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// topHistory is how many readings `whale top` keeps for its charts: two per
+// braille cell across a wide terminal.
+const topHistory = 2 * 400
+
+// runTop follows the container query: every interval it collects that
+// container alone and redraws its card with rolling CPU and memory charts,
+// until Ctrl+C, the q key, or count refreshes.
+func runTop(ctx context.Context, cli *client.Client, query string, opts dkr.CollectOptions, renderOpts ui.RenderOptions, interval time.Duration, noClear bool, count int) error {
+	snaps, err := dkr.ListContainers(ctx, opts.Lister, true)
+	if err != nil {
+		return err
+	}
+	target, err := resolveContainer(snaps, query)
+	if err != nil {
+		return err
+	}
+	opts.IncludeAll = true
+	opts.Filters = filters.NewArgs(filters.Arg("id", target.ID))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	keys, restoreTerm := readKeys()
+	defer restoreTerm()
+	// Live view: I/O as current rates.
+	renderOpts.ShowRates = true
+	rates := dkr.NewIORates()
+	hist := ui.DetailHistory{Interval: interval}
+	for n := 1; ; n++ {
+		opts.Lister.Next()
+		snaps, err := dkr.CollectSnapshots(ctx, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil // interrupted mid-collection
+			}
+			return err
+		}
+		if len(snaps) == 0 {
+			return fmt.Errorf("container %s is gone", target.Name)
+		}
+		rates.Apply(snaps)
+		debugStatsErrors(snaps)
+		enrich(ctx, cli, snaps)
+		s := snaps[0]
+		if s.State == "running" && !s.StatsUnavailable {
+			hist.CPU = appendCapped(hist.CPU, s.CPUPercent, topHistory)
+			hist.Mem = appendCapped(hist.Mem, float64(s.MemUsage), topHistory)
+		}
+		refreshScreen(noClear)
+		ui.RenderContainerDetail(os.Stdout, s, hist, renderOpts)
+		if n == count {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case k := <-keys:
+			if k == "q" || k == "Q" {
+				return nil
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// appendCapped appends v, dropping the oldest values beyond limit.
+func appendCapped(values []float64, v float64, limit int) []float64 {
+	values = append(values, v)
+	if len(values) > limit {
+		values = values[len(values)-limit:]
+	}
+	return values
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/filter"
+)
+
+// untilCond is a --until stop condition for watch mode. It is either a
+// container state ("container db exited") or a --where expression that must
+// hold for every shown container, optionally for a duration
+// ("cpu < 5 for 30s").
+type untilCond struct {
+	// Container form.
+	query string
+	state string
+	id    string
+
+	// Expression form.
+	expr  *filter.Expr
+	hold  time.Duration
+	since time.Time // when expr started holding; zero while it doesn't
+
+	met  bool
+	code int // exit code to use once met
+	why  string
+}
+
+// untilStates are the states accepted by the container form.
+var untilStates = map[string]bool{"exited": true, "running": true, "healthy": true, "removed": true}
+
+// unknownExitCode is the exit code for `exited` when the container was
+// removed before its exit code could be read, so it isn't mistaken for a
+// clean exit.
+const unknownExitCode = 255
+
+var untilForRe = regexp.MustCompile(`^(.*\S)\s+for\s+(\S+)$`)
+
+// parseUntil parses a --until condition.
+func parseUntil(src string) (*untilCond, error) {
+	f := strings.Fields(src)
+	if len(f) == 3 && f[0] == "container" {
+		state := strings.ToLower(f[2])
+		if !untilStates[state] {
+			return nil, fmt.Errorf("until: unknown state %q (want exited, running, healthy or removed)", f[2])
+		}
+		return &untilCond{query: f[1], state: state}, nil
+	}
+	u := &untilCond{}
+	exprSrc := src
+	if m := untilForRe.FindStringSubmatch(src); m != nil {
+		d, err := time.ParseDuration(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("until: %w", err)
+		}
+		exprSrc, u.hold = m[1], d
+	}
+	expr, err := filter.Compile(exprSrc)
+	if err != nil {
+		return nil, err
+	}
+	u.expr = expr
+	return u, nil
+}
+
+// resolve binds the container form to a concrete container up front, so a
+// container that later exits or is removed is still recognised.
+func (u *untilCond) resolve(ctx context.Context, lister *dkr.Lister) error {
+	if u.query == "" {
+		return nil
+	}
+	snaps, err := dkr.ListContainers(ctx, lister, true)
+	if err != nil {
+		return err
+	}
+	target, err := resolveContainer(snaps, u.query)
+	if err != nil {
+		return fmt.Errorf("until: %w", err)
+	}
+	u.id, u.query = target.ID, target.Name
+	return nil
+}
+
+// check evaluates the condition against the latest frame and records whether
+// it is met.
+func (u *untilCond) check(ctx context.Context, cli *client.Client, snaps []dkr.ContainerSnapshot, now time.Time) error {
+	if u.id != "" {
+		return u.checkContainer(ctx, cli)
+	}
+	// Rows without fresh stats read as zero, which would satisfy conditions
+	// like "cpu < 5" by itself, so they don't count either way.
+	all, checked := true, 0
+	for _, s := range snaps {
+		if s.Gone || s.StatsUnavailable || s.Stale {
+			continue
+		}
+		ok, err := u.expr.Match(s)
+		if err != nil {
+			return err
+		}
+		all, checked = all && ok, checked+1
+	}
+	all = all && checked > 0
+	if !all {
+		u.since = time.Time{}
+		return nil
+	}
+	if u.since.IsZero() {
+		u.since = now
+	}
+	if now.Sub(u.since) >= u.hold {
+		u.met, u.why = true, "condition held"
+		if u.hold > 0 {
+			u.why += " for " + u.hold.String()
+		}
+	}
+	return nil
+}
+
+func (u *untilCond) checkContainer(ctx context.Context, cli *client.Client) error {
+	info, err := cli.ContainerInspect(ctx, u.id)
+	if client.IsErrNotFound(err) {
+		switch u.state {
+		case "removed":
+			u.met, u.why = true, u.query+" was removed"
+		case "exited":
+			u.met, u.code = true, unknownExitCode
+			u.why = fmt.Sprintf("%s was removed, exit code unknown (%d)", u.query, unknownExitCode)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.ContainerJSONBase == nil || info.State == nil {
+		return errors.New("until: container state unavailable")
+	}
+	st := info.State
+	switch u.state {
+	case "exited":
+		if st.Status == "exited" || st.Status == "dead" {
+			u.met, u.code = true, st.ExitCode
+			u.why = fmt.Sprintf("%s exited (%d)", u.query, st.ExitCode)
+		}
+	case "running":
+		u.met = st.Running
+	case "healthy":
+		u.met = st.Health != nil && st.Health.Status == "healthy"
+	}
+	if u.met && u.why == "" {
+		u.why = u.query + " is " + u.state
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/docker/docker/client"
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/ui"
+)
+
+// runWait polls until every container matching filterSpecs is running (and
+// healthy, with requireHealthy) or timeout passes. On timeout it prints the
+// containers that are not ready and returns an error, so CI scripts can use
+// it in place of sleep loops.
+func runWait(ctx context.Context, cli *client.Client, lister *dkr.Lister, filterSpecs []string, requireHealthy bool, timeout, interval time.Duration) error {
+	f, err := dkr.ParseFilters(filterSpecs)
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	var last []dkr.ReadyStatus
+	for {
+		lister.Next()
+		statuses, err := dkr.CheckReady(ctx, cli, lister, f, requireHealthy)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if err == nil {
+			last = statuses
+			if allReady(statuses) {
+				fmt.Fprintf(os.Stderr, "%d container(s) ready after %s\n", len(statuses), time.Since(start).Round(time.Second))
+				return nil
+			}
+			slog.Debug("waiting", "ready", countReady(statuses), "total", len(statuses))
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			if len(last) == 0 {
+				return errors.New("timed out: no containers match the filter")
+			}
+			if err := ui.RenderReadiness(last, os.Stderr); err != nil {
+				return err
+			}
+			return fmt.Errorf("timed out: %d of %d container(s) not ready", len(last)-countReady(last), len(last))
+		}
+	}
+}
+
+// allReady reports whether statuses is non-empty and every entry is ready.
+// An empty match keeps waiting, since the containers may not exist yet.
+func allReady(statuses []dkr.ReadyStatus) bool {
+	return len(statuses) > 0 && countReady(statuses) == len(statuses)
+}
+
+func countReady(statuses []dkr.ReadyStatus) int {
+	n := 0
+	for _, s := range statuses {
+		if s.Ready {
+			n++
+		}
+	}
+	return n
+}
@@ -6,7 +6,9 @@ toolchain go1.24.5
 
 require (
 	github.com/docker/docker v28.4.0+incompatible
+	github.com/docker/go-units v0.5.0
 	github.com/jedib0t/go-pretty/v6 v6.6.8
+	golang.org/x/sys v0.36.0
 )
 
 require (
@@ -20,7 +22,6 @@ require (
 	github.com/containerd/log v0.1.0 // indirect
 	github.com/distribution/reference v0.6.0 // indirect
 	github.com/docker/go-connections v0.6.0 // indirect
-	github.com/docker/go-units v0.5.0 // indirect
 	github.com/felixge/httpsnoop v1.0.4 // indirect
 	github.com/go-logr/logr v1.4.3 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
@@ -38,7 +39,6 @@ require (
 	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.38.0 // indirect
 	go.opentelemetry.io/otel/metric v1.38.0 // indirect
 	go.opentelemetry.io/otel/trace v1.38.0 // indirect
-	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/term v0.35.0
 	golang.org/x/text v0.29.0 // indirect
 	golang.org/x/time v0.13.0 // indirect
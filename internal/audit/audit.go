@@ -0,0 +1,104 @@
+// Package audit records the changes whale makes to containers, one JSON
+// line per action, so operators of shared hosts can see what the tool did.
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is one action taken on one container.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user"`
+	Host      string    `json:"host"`
+	Action    string    `json:"action"` // stop, restart, rm, exec
+	Container string    `json:"container"`
+	ID        string    `json:"id"`
+	Detail    string    `json:"detail,omitempty"` // e.g. the exec command
+	Reason    string    `json:"reason,omitempty"` // from --reason
+	Error     string    `json:"error,omitempty"`  // empty when it succeeded
+}
+
+// DefaultPath returns the per-user audit log location, e.g.
+// ~/.config/whale/audit.jsonl on Linux.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "whale", "audit.jsonl")
+}
+
+// Log appends entries to an audit file.
+type Log struct {
+	mu     sync.Mutex
+	f      *os.File
+	user   string
+	host   string
+	reason string
+}
+
+// Open opens (creating if needed) the audit file at path. Callers open it
+// before acting so an unwritable log stops the action instead of leaving
+// it unrecorded.
+func Open(path, reason string) (*Log, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	return &Log{f: f, user: currentUser(), host: host, reason: reason}, nil
+}
+
+// Record appends one entry; err is the action's outcome.
+func (l *Log) Record(action, container, id, detail string, err error) error {
+	e := Entry{
+		Time:      time.Now().UTC(),
+		User:      l.user,
+		Host:      l.host,
+		Action:    action,
+		Container: container,
+		ID:        id,
+		Detail:    detail,
+		Reason:    l.reason,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	data, mErr := json.Marshal(e)
+	if mErr != nil {
+		return mErr
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, wErr := l.f.Write(append(data, '\n'))
+	return wErr
+}
+
+// Close closes the file.
+func (l *Log) Close() error {
+	return l.f.Close()
+}
+
+// currentUser names who ran whale, including the original user behind sudo.
+func currentUser() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if sudo := os.Getenv("SUDO_USER"); sudo != "" && sudo != name {
+		name = sudo + " (as " + name + ")"
+	}
+	return name
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Config is whale's optional configuration file. Defaults and views map
+// flag names (without dashes) to values, e.g. {"sort": "mem", "all": true}.
+type Config struct {
+	// Defaults apply to every invocation.
+	Defaults map[string]any `json:"defaults"`
+	// Views are named flag sets selected with --view.
+	Views map[string]map[string]any `json:"views"`
+	// Exporters are exporter specs ("name:arg", see pkg/export) that run
+	// on every collection in addition to those given with --export.
+	Exporters []string `json:"exporters"`
+}
+
+// DefaultPath returns the per-user config location, e.g.
+// ~/.config/whale/config.json on Linux.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "whale", "config.json")
+}
+
+// Load reads the config at path. A missing file yields an empty config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// View returns the flag values of the named view.
+func (c *Config) View(name string) (map[string]any, error) {
+	v, ok := c.Views[name]
+	if !ok {
+		names := make([]string, 0, len(c.Views))
+		for n := range c.Views {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown view %q (no views configured)", name)
+		}
+		return nil, fmt.Errorf("unknown view %q (available: %v)", name, names)
+	}
+	return v, nil
+}
+
+// FormatValue renders a JSON config value the way it would be typed on the
+// command line, so it can be passed to flag.Set.
+func FormatValue(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case []any:
+		// Lists map to comma-separated flag values.
+		parts := make([]string, len(x))
+		for i, e := range x {
+			parts[i] = FormatValue(e)
+		}
+		return strings.Join(parts, ",")
+	case float64:
+		// JSON numbers decode as float64; keep integers free of exponents.
+		if x == float64(int64(x)) {
+			return fmt.Sprintf("%d", int64(x))
+		}
+		return fmt.Sprint(x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
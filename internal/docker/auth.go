@@ -0,0 +1,109 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/registry"
+)
+
+// dockerHubAuthKey is how the Docker CLI keys Docker Hub credentials.
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// dockerConfig is the part of ~/.docker/config.json needed for registry auth.
+type dockerConfig struct {
+	Auths       map[string]struct{ Auth string } `json:"auths"`
+	CredsStore  string                           `json:"credsStore"`
+	CredHelpers map[string]string                `json:"credHelpers"`
+}
+
+// loadDockerConfig reads $DOCKER_CONFIG/config.json, or ~/.docker/config.json.
+func loadDockerConfig() dockerConfig {
+	var cfg dockerConfig
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return cfg
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	return cfg
+}
+
+// registryHost returns the registry an image reference points at, using
+// the Docker CLI's rule: a first path component with a dot or a port, or
+// "localhost", is a registry; anything else is Docker Hub.
+func registryHost(ref string) string {
+	first, _, ok := strings.Cut(ref, "/")
+	if ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "docker.io"
+}
+
+// registryAuth returns the encoded credentials for ref's registry from the
+// Docker CLI config, as ImagePull and DistributionInspect expect them, or ""
+// to try anonymously. Credential helpers (credsStore/credHelpers) are run
+// the way the Docker CLI runs them.
+func registryAuth(cfg dockerConfig, ref string) string {
+	host := registryHost(ref)
+	key := host
+	if host == "docker.io" {
+		key = dockerHubAuthKey
+	}
+	auth := registry.AuthConfig{ServerAddress: key}
+	if helper := cfg.CredHelpers[host]; helper != "" {
+		if !credHelper(helper, key, &auth) {
+			return ""
+		}
+	} else if entry, ok := cfg.Auths[key]; ok && entry.Auth != "" {
+		raw, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return ""
+		}
+		user, pass, _ := strings.Cut(string(raw), ":")
+		auth.Username, auth.Password = user, pass
+	} else if cfg.CredsStore != "" {
+		if !credHelper(cfg.CredsStore, key, &auth) {
+			return ""
+		}
+	} else {
+		return ""
+	}
+	encoded, err := registry.EncodeAuthConfig(auth)
+	if err != nil {
+		return ""
+	}
+	return encoded
+}
+
+// credHelper runs docker-credential-<name> get for server.
+func credHelper(name, server string, auth *registry.AuthConfig) bool {
+	cmd := exec.Command("docker-credential-"+name, "get")
+	cmd.Stdin = strings.NewReader(server)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return false
+	}
+	var creds struct{ Username, Secret string }
+	if err := json.Unmarshal(out.Bytes(), &creds); err != nil {
+		return false
+	}
+	if creds.Username == "<token>" {
+		auth.IdentityToken = creds.Secret
+	} else {
+		auth.Username, auth.Password = creds.Username, creds.Secret
+	}
+	return true
+}
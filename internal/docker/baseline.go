@@ -0,0 +1,89 @@
+package docker
+
+import "math"
+
+// Baseline keeps a rolling per-container history of CPU and memory and flags
+// readings more than Sigma standard deviations from the mean, so unusual
+// usage stands out even when it isn't high. Containers are keyed by name,
+// which survives recreation, so recorded snapshots can seed the history.
+type Baseline struct {
+	Sigma float64
+	// Window is how many samples per container the baseline covers.
+	Window int
+	cpu    map[string][]float64
+	mem    map[string][]float64
+}
+
+// baselineMinSamples is how much history a container needs before its
+// readings are judged at all.
+const baselineMinSamples = 10
+
+// NewBaseline returns a baseline over the last window samples.
+func NewBaseline(sigma float64, window int) *Baseline {
+	return &Baseline{Sigma: sigma, Window: window, cpu: map[string][]float64{}, mem: map[string][]float64{}}
+}
+
+// Seed adds recorded snapshots, oldest first, to the history.
+func (b *Baseline) Seed(history [][]ContainerSnapshot) {
+	for _, snaps := range history {
+		for _, s := range snaps {
+			b.record(s)
+		}
+	}
+}
+
+// Apply sets CPUAnomaly and MemAnomaly on snaps against the history so far,
+// then adds the readings to it.
+func (b *Baseline) Apply(snaps []ContainerSnapshot) {
+	for i := range snaps {
+		s := &snaps[i]
+		if !hasStats(*s) {
+			continue
+		}
+		// Floors keep near-constant series (an idle container at 0.1% CPU)
+		// from flagging every small wobble.
+		s.CPUAnomaly = b.deviates(b.cpu[s.Name], s.CPUPercent, 1)
+		s.MemAnomaly = b.deviates(b.mem[s.Name], float64(s.MemUsage), 8<<20)
+		b.record(*s)
+	}
+}
+
+func (b *Baseline) record(s ContainerSnapshot) {
+	if !hasStats(s) {
+		return
+	}
+	b.cpu[s.Name] = appendWindow(b.cpu[s.Name], s.CPUPercent, b.Window)
+	b.mem[s.Name] = appendWindow(b.mem[s.Name], float64(s.MemUsage), b.Window)
+}
+
+// deviates reports whether x is more than Sigma standard deviations (at
+// least floor) from the mean of hist.
+func (b *Baseline) deviates(hist []float64, x, floor float64) bool {
+	if len(hist) < baselineMinSamples {
+		return false
+	}
+	var sum float64
+	for _, v := range hist {
+		sum += v
+	}
+	mean := sum / float64(len(hist))
+	var sq float64
+	for _, v := range hist {
+		sq += (v - mean) * (v - mean)
+	}
+	std := math.Max(math.Sqrt(sq/float64(len(hist))), floor)
+	return math.Abs(x-mean) > b.Sigma*std
+}
+
+// hasStats reports whether s carries a real stats reading.
+func hasStats(s ContainerSnapshot) bool {
+	return s.State == "running" && !s.StatsUnavailable && !s.Stale && s.MemUsage > 0
+}
+
+func appendWindow(h []float64, v float64, window int) []float64 {
+	h = append(h, v)
+	if len(h) > window {
+		h = h[len(h)-window:]
+	}
+	return h
+}
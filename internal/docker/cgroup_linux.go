@@ -0,0 +1,258 @@
+//go:build linux
+
+package docker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNoCgroup means a container's cgroup directory was not found, e.g. with
+// a rootless daemon or an unusual cgroup driver layout.
+var errNoCgroup = errors.New("cgroup not found")
+
+// cgroupFirstWindow is how long Populate waits between two CPU readings for
+// a container it has no previous reading of (the first collection).
+const cgroupFirstWindow = 250 * time.Millisecond
+
+// CgroupCollector reads container metrics straight from the cgroup v2
+// filesystem (and network counters from /proc), bypassing the daemon's stats
+// API. It only works when whale runs on the Docker host itself. It keeps the
+// previous CPU reading per container, so reuse one collector across watch
+// refreshes.
+type CgroupCollector struct {
+	root     string
+	memTotal uint64 // host memory, used when a container has no limit
+
+	mu   sync.Mutex
+	dirs map[string]string // container ID -> cgroup directory
+	prev map[string]cpuSample
+}
+
+type cpuSample struct {
+	usage uint64 // usage_usec
+	at    time.Time
+}
+
+// NewCgroupCollector checks for a cgroup v2 hierarchy at /sys/fs/cgroup.
+func NewCgroupCollector() (*CgroupCollector, error) {
+	root := "/sys/fs/cgroup"
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return nil, errors.New("cgroup collector needs cgroup v2 mounted at /sys/fs/cgroup")
+	}
+	return &CgroupCollector{
+		root:     root,
+		memTotal: hostMemTotal(),
+		dirs:     map[string]string{},
+		prev:     map[string]cpuSample{},
+	}, nil
+}
+
+// Populate fills snap's metrics from its cgroup. It returns errNoCgroup when
+// the container's cgroup cannot be located, so the caller can fall back to
+// the stats API.
+func (c *CgroupCollector) Populate(ctx context.Context, snap *ContainerSnapshot) error {
+	dir, err := c.dir(snap.ID)
+	if err != nil {
+		return err
+	}
+	usage, err := readKeyedValue(filepath.Join(dir, "cpu.stat"), "usage_usec")
+	if err != nil {
+		c.forget(snap.ID)
+		return err
+	}
+	now := time.Now()
+	c.mu.Lock()
+	prev, ok := c.prev[snap.ID]
+	c.mu.Unlock()
+	if !ok {
+		// No earlier reading: take a short window now.
+		prev = cpuSample{usage: usage, at: now}
+		select {
+		case <-time.After(cgroupFirstWindow):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if usage, err = readKeyedValue(filepath.Join(dir, "cpu.stat"), "usage_usec"); err != nil {
+			return err
+		}
+		now = time.Now()
+	}
+	c.mu.Lock()
+	c.prev[snap.ID] = cpuSample{usage: usage, at: now}
+	c.mu.Unlock()
+
+	snap.CPUTime = time.Duration(usage) * time.Microsecond
+	snap.CPUPercent = 0
+	if elapsed := now.Sub(prev.at).Microseconds(); elapsed > 0 && usage > prev.usage {
+		// 100% is one full CPU, like the stats API.
+		snap.CPUPercent = float64(usage-prev.usage) / float64(elapsed) * 100.0
+	}
+
+	snap.MemUsage, _ = readUint(filepath.Join(dir, "memory.current"))
+	snap.MemLimit, err = readUint(filepath.Join(dir, "memory.max"))
+	if err != nil || snap.MemLimit == 0 { // "max": unlimited
+		snap.MemLimit = c.memTotal
+	}
+	snap.MemPercent = 0
+	if snap.MemLimit > 0 {
+		snap.MemPercent = float64(snap.MemUsage) / float64(snap.MemLimit) * 100.0
+	}
+	if pids, err := readUint(filepath.Join(dir, "pids.current")); err == nil {
+		snap.PIDs = int(pids)
+	}
+	if limit, err := readUint(filepath.Join(dir, "pids.max")); err == nil {
+		snap.PIDsLimit = pidsLimit(limit)
+	}
+	snap.BlockRead, snap.BlockWrite = readIOStat(filepath.Join(dir, "io.stat"))
+	snap.NetRx, snap.NetTx = 0, 0
+	if pid := firstPID(dir); pid != "" {
+		snap.NetRx, snap.NetTx = readNetDev(filepath.Join("/proc", pid, "net", "dev"))
+	}
+	return nil
+}
+
+// dir finds (and caches) the container's cgroup directory for the systemd
+// and cgroupfs drivers.
+func (c *CgroupCollector) dir(id string) (string, error) {
+	c.mu.Lock()
+	dir, ok := c.dirs[id]
+	c.mu.Unlock()
+	if ok {
+		return dir, nil
+	}
+	for _, d := range []string{
+		filepath.Join(c.root, "system.slice", "docker-"+id+".scope"),
+		filepath.Join(c.root, "docker", id),
+	} {
+		if _, err := os.Stat(filepath.Join(d, "cpu.stat")); err == nil {
+			c.mu.Lock()
+			c.dirs[id] = d
+			c.mu.Unlock()
+			return d, nil
+		}
+	}
+	return "", errNoCgroup
+}
+
+func (c *CgroupCollector) forget(id string) {
+	c.mu.Lock()
+	delete(c.dirs, id)
+	delete(c.prev, id)
+	c.mu.Unlock()
+}
+
+// readUint reads a file holding a single number; "max" reads as 0.
+func readUint(path string) (uint64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	s := strings.TrimSpace(string(b))
+	if s == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// readKeyedValue returns key's value from a "key value [unit]" per line
+// file such as cpu.stat or /proc/meminfo.
+func readKeyedValue(path, key string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if f := strings.Fields(sc.Text()); len(f) >= 2 && f[0] == key {
+			return strconv.ParseUint(f[1], 10, 64)
+		}
+	}
+	return 0, errors.New(path + ": no " + key)
+}
+
+// readIOStat sums rbytes and wbytes across devices in io.stat.
+func readIOStat(path string) (read, write uint64) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		for _, field := range strings.Fields(line) {
+			k, v, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			n, _ := strconv.ParseUint(v, 10, 64)
+			switch k {
+			case "rbytes":
+				read += n
+			case "wbytes":
+				write += n
+			}
+		}
+	}
+	return read, write
+}
+
+// firstPID returns a process in the cgroup, whose network namespace is the
+// container's.
+func firstPID(dir string) string {
+	b, err := os.ReadFile(filepath.Join(dir, "cgroup.procs"))
+	if err != nil {
+		return ""
+	}
+	pid, _, _ := strings.Cut(strings.TrimSpace(string(b)), "\n")
+	return pid
+}
+
+// readNetDev sums received and transmitted bytes over all interfaces but
+// loopback in a /proc/<pid>/net/dev file.
+func readNetDev(path string) (rx, tx uint64) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		iface, rest, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(iface) == "lo" {
+			continue
+		}
+		f := strings.Fields(rest)
+		if len(f) < 9 {
+			continue
+		}
+		r, _ := strconv.ParseUint(f[0], 10, 64)
+		t, _ := strconv.ParseUint(f[8], 10, 64)
+		rx += r
+		tx += t
+	}
+	return rx, tx
+}
+
+// hostMemTotal reads MemTotal from /proc/meminfo, in bytes.
+func hostMemTotal() uint64 {
+	kb, err := readKeyedValue("/proc/meminfo", "MemTotal:")
+	if err != nil {
+		return 0
+	}
+	return kb * 1024
+}
+
+// HostNetDev returns the host's received and transmitted bytes across all
+// interfaces but loopback, from /proc/net/dev.
+func HostNetDev() (rx, tx uint64, ok bool) {
+	if _, err := os.Stat("/proc/net/dev"); err != nil {
+		return 0, 0, false
+	}
+	rx, tx = readNetDev("/proc/net/dev")
+	return rx, tx, true
+}
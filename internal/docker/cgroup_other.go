@@ -0,0 +1,27 @@
+//go:build !linux
+
+package docker
+
+import (
+	"context"
+	"errors"
+)
+
+// CgroupCollector reads container metrics from the cgroup filesystem; it is
+// only available on Linux.
+type CgroupCollector struct{}
+
+// NewCgroupCollector always fails outside Linux.
+func NewCgroupCollector() (*CgroupCollector, error) {
+	return nil, errors.New("cgroup collector is only available on Linux")
+}
+
+// Populate is never reached, since no collector can be created.
+func (c *CgroupCollector) Populate(context.Context, *ContainerSnapshot) error {
+	return errors.New("cgroup collector is only available on Linux")
+}
+
+// HostNetDev is unavailable without procfs.
+func HostNetDev() (rx, tx uint64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,123 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// Arrivals marks containers that appeared since the previous collection, so
+// watch mode can highlight fresh deployments (and unexpected containers) for
+// a few refreshes.
+type Arrivals struct {
+	// Frames is how many refreshes a new container stays marked.
+	Frames int
+	left   map[string]int // ID -> frames the mark remains
+	primed bool
+}
+
+// NewArrivals returns a tracker that keeps the mark for frames refreshes.
+func NewArrivals(frames int) *Arrivals {
+	return &Arrivals{Frames: frames, left: map[string]int{}}
+}
+
+// Apply sets New on snaps that were not present last time. The first call
+// only records the initial set: everything is new when whale starts.
+func (a *Arrivals) Apply(snaps []ContainerSnapshot) {
+	seen := make(map[string]struct{}, len(snaps))
+	for i := range snaps {
+		s := &snaps[i]
+		seen[s.ID] = struct{}{}
+		n, known := a.left[s.ID]
+		if !known {
+			n = 0
+			if a.primed {
+				n = a.Frames
+			}
+		}
+		if n > 0 {
+			s.New = true
+			n--
+		}
+		a.left[s.ID] = n
+	}
+	for id := range a.left {
+		if _, ok := seen[id]; !ok {
+			delete(a.left, id)
+		}
+	}
+	a.primed = true
+}
+
+// Departures keeps containers that vanished from the list visible for a few
+// refreshes, marked Gone, so a crash or removal in watch mode doesn't just
+// silently drop a row.
+type Departures struct {
+	// Frames is how many refreshes a departed container stays listed.
+	Frames int
+	prev   map[string]ContainerSnapshot
+	gone   map[string]departed
+}
+
+type departed struct {
+	snap ContainerSnapshot
+	left int
+}
+
+// NewDepartures returns a tracker that keeps departed rows for frames refreshes.
+func NewDepartures(frames int) *Departures {
+	return &Departures{Frames: frames, prev: map[string]ContainerSnapshot{}, gone: map[string]departed{}}
+}
+
+// Apply returns snaps with rows for recently departed containers appended.
+// Each departure is inspected once to tell an exit (with its code) from a
+// removal.
+func (d *Departures) Apply(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot) []ContainerSnapshot {
+	current := make(map[string]ContainerSnapshot, len(snaps))
+	for _, s := range snaps {
+		current[s.ID] = s
+		delete(d.gone, s.ID) // came back (restarted or listed again)
+	}
+	for id, s := range d.prev {
+		if _, ok := current[id]; ok || s.Gone {
+			continue
+		}
+		ghost := s
+		clearMetrics(&ghost)
+		ghost.Gone, ghost.New, ghost.Stale, ghost.StatsUnavailable = true, false, false, false
+		ghost.Status = departureStatus(ctx, cli, id)
+		// Not "running" any more, so enrichment skips it.
+		ghost.State = "gone"
+		d.gone[id] = departed{snap: ghost, left: d.Frames}
+	}
+	d.prev = current
+	for id, g := range d.gone {
+		if g.left <= 0 {
+			delete(d.gone, id)
+			continue
+		}
+		snaps = append(snaps, g.snap)
+		g.left--
+		d.gone[id] = g
+	}
+	return snaps
+}
+
+// departureStatus describes what happened to a container that left the list.
+func departureStatus(ctx context.Context, cli *client.Client, id string) string {
+	cctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	info, err := cli.ContainerInspect(cctx, id)
+	switch {
+	case client.IsErrNotFound(err):
+		return "gone (removed)"
+	case err != nil || info.ContainerJSONBase == nil || info.State == nil:
+		return "gone"
+	case info.State.Status == "exited" || info.State.Status == "dead":
+		return fmt.Sprintf("gone (exited %d)", info.State.ExitCode)
+	default:
+		return "gone (" + info.State.Status + ")"
+	}
+}
@@ -2,23 +2,35 @@ package docker
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/client"
 )
 
+// MaxInFlight caps the number of simultaneous connections to the daemon for
+// the whole process, independent of the per-collection concurrency. With
+// hundreds of containers this keeps whale from exhausting unix-socket
+// connections or file descriptors; excess requests queue on the transport.
+const MaxInFlight = 128
+
 // NewClient creates a Docker API client using environment variables and
 // negotiates the API version with the daemon for compatibility.
 func NewClient(ctx context.Context) (*client.Client, error) {
-	// Tuned HTTP transport for high parallelism and fast reuse
+	// Tuned HTTP transport for high parallelism and fast reuse. Idle
+	// connections are kept up to the in-flight cap so every watch tick can
+	// reuse the previous tick's connections instead of dialing again.
 	transport := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
 		DialContext:           (&net.Dialer{Timeout: 2 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
 		ForceAttemptHTTP2:     false,
-		MaxIdleConns:          200,
-		MaxIdleConnsPerHost:   200,
+		MaxConnsPerHost:       MaxInFlight,
+		MaxIdleConns:          MaxInFlight,
+		MaxIdleConnsPerHost:   MaxInFlight,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   2 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
@@ -36,3 +48,34 @@ func NewClient(ctx context.Context) (*client.Client, error) {
 	}
 	return cli, nil
 }
+
+// callTimeout is context.WithTimeout for a single daemon request, except
+// that the clock starts once the request has a connection: time spent
+// queued behind MaxInFlight on the transport makes a saturated host slower,
+// not a source of failed calls.
+func callTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	var (
+		mu    sync.Mutex
+		timer *time.Timer
+		done  bool
+	)
+	trace := &httptrace.ClientTrace{GotConn: func(httptrace.GotConnInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		if timer == nil && !done {
+			timer = time.AfterFunc(d, func() {
+				cancel(fmt.Errorf("daemon call: %w after %s", context.DeadlineExceeded, d))
+			})
+		}
+	}}
+	return httptrace.WithClientTrace(ctx, trace), func() {
+		mu.Lock()
+		done = true
+		if timer != nil {
+			timer.Stop()
+		}
+		mu.Unlock()
+		cancel(context.Canceled)
+	}
+}
@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// Collector is the container runtime whale reads from. DockerCollector is
+// the implementation whale uses; other runtimes (Podman, containerd) or fakes
+// in tests can stand in for it through CollectOptions.Collector. It is
+// public as whale.Collector (pkg/whale).
+type Collector interface {
+	// ListContainers returns the containers matching f as snapshots
+	// without stats, stopped ones too when all is set.
+	ListContainers(ctx context.Context, all bool, f filters.Args) ([]ContainerSnapshot, error)
+	// Stats fills the metrics of a listed, running container from a single
+	// reading.
+	Stats(ctx context.Context, snap *ContainerSnapshot) error
+	// Networks groups containers by the networks they are connected to, as
+	// CollectNetworks does.
+	Networks(ctx context.Context, all bool) (map[string][]ContainerNetInfo, error)
+}
+
+// ExitReader is implemented by collectors that can tell when a stopped
+// container exited and with which code. Without it, stopped containers keep
+// their listed status.
+type ExitReader interface {
+	Exit(ctx context.Context, id string) (code int, finished time.Time, err error)
+}
+
+// Sampler is implemented by collectors that can measure CPU over a chosen
+// window for CollectOptions.Sample. Without it, Sample is ignored.
+type Sampler interface {
+	// FirstSample takes the opening reading of a running container.
+	FirstSample(ctx context.Context, id string) (*container.Stats, error)
+	// StatsSince fills snap's metrics from a second reading, with CPU
+	// measured since first.
+	StatsSince(ctx context.Context, snap *ContainerSnapshot, first *container.Stats) error
+}
+
+// DockerCollector collects from the Docker daemon behind cli.
+type DockerCollector struct {
+	cli    *client.Client
+	lister *Lister
+}
+
+var (
+	_ Collector  = (*DockerCollector)(nil)
+	_ ExitReader = (*DockerCollector)(nil)
+	_ Sampler    = (*DockerCollector)(nil)
+)
+
+// NewDockerCollector returns a Collector for cli. l, when non-nil, supplies
+// the container list, shared with other views in the same refresh.
+func NewDockerCollector(cli *client.Client, l *Lister) *DockerCollector {
+	if l == nil {
+		l = NewLister(cli)
+	}
+	return &DockerCollector{cli: cli, lister: l}
+}
+
+func (d *DockerCollector) ListContainers(ctx context.Context, all bool, f filters.Args) ([]ContainerSnapshot, error) {
+	return listSnapshots(ctx, d.cli, d.lister, all, f)
+}
+
+func (d *DockerCollector) Stats(ctx context.Context, snap *ContainerSnapshot) error {
+	return populateStats(ctx, d.cli, snap, snap.ID, nil, false)
+}
+
+func (d *DockerCollector) Networks(ctx context.Context, all bool) (map[string][]ContainerNetInfo, error) {
+	return CollectNetworks(ctx, d.lister, all)
+}
+
+// Exit reads the exit code and finish time from inspect. finished is zero
+// when the daemon doesn't report it.
+func (d *DockerCollector) Exit(ctx context.Context, id string) (int, time.Time, error) {
+	info, err := d.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if info.ContainerJSONBase == nil || info.State == nil {
+		return 0, time.Time{}, errors.New("container state unavailable")
+	}
+	var finished time.Time
+	if t, err := time.Parse(time.RFC3339Nano, info.State.FinishedAt); err == nil && t.Year() > 1 {
+		finished = t
+	}
+	return info.State.ExitCode, finished, nil
+}
+
+// FirstSample takes a one-shot stats reading, skipping the daemon's own
+// pre-sample.
+func (d *DockerCollector) FirstSample(ctx context.Context, id string) (*container.Stats, error) {
+	return readStats(ctx, d.cli, id, true)
+}
+
+func (d *DockerCollector) StatsSince(ctx context.Context, snap *ContainerSnapshot, first *container.Stats) error {
+	return populateStats(ctx, d.cli, snap, snap.ID, first, true)
+}
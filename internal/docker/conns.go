@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// CountConnections sets Conns on running snapshots to the number of
+// established TCP connections (IPv4 and IPv6) in the container's network
+// namespace. On a Linux Docker host it reads /proc/<pid>/net/tcp directly;
+// otherwise it runs cat on the same files inside the container. Containers
+// on the host network are skipped, since the count would be the host's.
+func CountConnections(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot) {
+	forEachRunning(snaps, func(s *ContainerSnapshot) {
+		if s.HostNetwork {
+			return
+		}
+		cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		info, err := cli.ContainerInspect(cctx, s.ID)
+		if err != nil || info.State == nil {
+			slog.Debug("inspecting container failed", "container", s.Name, "err", err)
+			return
+		}
+		tables, ok := procNetTCP(info.State.Pid)
+		if !ok {
+			out, code, err := ExecOutput(cctx, cli, s.ID, []string{"cat", "/proc/net/tcp", "/proc/net/tcp6"})
+			if err != nil || code != 0 && out == "" {
+				slog.Debug("reading connections by exec failed", "container", s.Name, "exit_code", code, "err", err)
+				return
+			}
+			tables = out
+		}
+		n := countEstablished(tables)
+		s.Conns = &n
+	})
+}
+
+// tcpEstablished is the ESTABLISHED state in /proc/net/tcp's st column.
+const tcpEstablished = "01"
+
+// countEstablished counts ESTABLISHED sockets in the concatenated contents
+// of /proc/net/tcp and tcp6. Header lines have "st" in that column.
+func countEstablished(tables string) int {
+	n := 0
+	for _, line := range strings.Split(tables, "\n") {
+		// sl local_address rem_address st ...
+		if f := strings.Fields(line); len(f) > 3 && f[3] == tcpEstablished {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,23 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// procNetTCP reads the TCP socket tables of the network namespace of host
+// process pid. It fails when whale can't see the process, e.g. when the
+// daemon is remote or runs in a VM.
+func procNetTCP(pid int) (string, bool) {
+	if pid <= 0 {
+		return "", false
+	}
+	dir := filepath.Join("/proc", strconv.Itoa(pid), "net")
+	tcp, err := os.ReadFile(filepath.Join(dir, "tcp"))
+	if err != nil {
+		return "", false
+	}
+	tcp6, _ := os.ReadFile(filepath.Join(dir, "tcp6")) // absent with IPv6 disabled
+	return string(tcp) + string(tcp6), true
+}
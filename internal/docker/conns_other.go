@@ -0,0 +1,8 @@
+//go:build !linux
+
+package docker
+
+// procNetTCP needs procfs; outside Linux the tables are read by exec.
+func procNetTCP(pid int) (string, bool) {
+	return "", false
+}
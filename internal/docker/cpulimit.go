@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// CPULimits reads each running container's CPU quota (--cpus, or
+// --cpu-quota/--cpu-period) once and caches it by ID, so watch mode only
+// inspects containers it hasn't seen before.
+type CPULimits struct {
+	mu     sync.Mutex
+	limits map[string]float64 // cores; 0 = unlimited
+}
+
+// NewCPULimits returns an empty cache.
+func NewCPULimits() *CPULimits {
+	return &CPULimits{limits: map[string]float64{}}
+}
+
+// Apply sets CPULimit on running snapshots. Containers that cannot be
+// inspected are left at zero and retried next time.
+func (c *CPULimits) Apply(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot) {
+	forEachRunning(snaps, func(s *ContainerSnapshot) {
+		c.mu.Lock()
+		limit, ok := c.limits[s.ID]
+		c.mu.Unlock()
+		if !ok {
+			cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			info, err := cli.ContainerInspect(cctx, s.ID)
+			if err != nil || info.HostConfig == nil {
+				slog.Debug("inspecting container failed", "container", s.Name, "err", err)
+				return
+			}
+			hc := info.HostConfig
+			switch {
+			case hc.NanoCPUs > 0:
+				limit = float64(hc.NanoCPUs) / 1e9
+			case hc.CPUQuota > 0:
+				period := hc.CPUPeriod
+				if period <= 0 {
+					period = 100000 // the kernel's default CFS period, in µs
+				}
+				limit = float64(hc.CPUQuota) / float64(period)
+			}
+			c.mu.Lock()
+			c.limits[s.ID] = limit
+			c.mu.Unlock()
+		}
+		s.CPULimit = limit
+	})
+}
@@ -0,0 +1,209 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// ComposeServiceLabel names the Compose service a container belongs to.
+const ComposeServiceLabel = "com.docker.compose.service"
+
+// ComposeProject is the desired state from a Compose file, as normalised
+// by `docker compose config` (env files, interpolation and extends applied).
+type ComposeProject struct {
+	Name     string                    `json:"name"`
+	Services map[string]ComposeService `json:"services"`
+}
+
+// ComposeService is the part of a service definition drift compares.
+type ComposeService struct {
+	Image       string             `json:"image"`
+	Environment map[string]*string `json:"environment"`
+	Ports       []struct {
+		Target    int    `json:"target"`
+		Published string `json:"published"`
+		Protocol  string `json:"protocol"`
+	} `json:"ports"`
+	Deploy *struct {
+		Replicas *int `json:"replicas"`
+	} `json:"deploy"`
+}
+
+// LoadCompose resolves a Compose file with the Docker CLI's compose plugin,
+// so whale reads it exactly as `docker compose up` would. An empty file uses
+// compose's own lookup (compose.yaml, docker-compose.yml...).
+func LoadCompose(ctx context.Context, file string) (*ComposeProject, error) {
+	args := []string{"compose"}
+	if file != "" {
+		args = append(args, "-f", file)
+	}
+	cmd := exec.CommandContext(ctx, "docker", append(args, "config", "--format", "json")...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("docker compose config: %s", msg)
+		}
+		return nil, fmt.Errorf("docker compose config: %w", err)
+	}
+	var p ComposeProject
+	if err := json.Unmarshal(stdout.Bytes(), &p); err != nil {
+		return nil, fmt.Errorf("docker compose config: %w", err)
+	}
+	return &p, nil
+}
+
+// Drift is one difference between the Compose file and what is deployed.
+type Drift struct {
+	Service   string `json:"service"`
+	Container string `json:"container,omitempty"`
+	// Kind is missing, stopped, extra, replicas, image, env or ports.
+	Kind string `json:"kind"`
+	Want string `json:"want,omitempty"`
+	Have string `json:"have,omitempty"`
+}
+
+// CheckDrift compares p with the project's containers (matched by the
+// Compose project and service labels) and returns the differences, ordered
+// by service. Environment variables the image sets but the file doesn't
+// mention are not drift.
+func CheckDrift(ctx context.Context, cli *client.Client, p *ComposeProject) ([]Drift, error) {
+	list, err := cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", ComposeProjectLabel+"="+p.Name)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	byService := map[string][]container.Summary{}
+	for _, c := range list {
+		byService[c.Labels[ComposeServiceLabel]] = append(byService[c.Labels[ComposeServiceLabel]], c)
+	}
+	var out []Drift
+	for svc, cs := range byService {
+		if _, ok := p.Services[svc]; !ok {
+			for _, c := range cs {
+				out = append(out, Drift{Service: svc, Container: deriveName(c.Names), Kind: "extra", Have: c.State})
+			}
+		}
+	}
+	for name, svc := range p.Services {
+		cs := byService[name]
+		if len(cs) == 0 {
+			out = append(out, Drift{Service: name, Kind: "missing", Want: svc.Image})
+			continue
+		}
+		running := 0
+		for _, c := range cs {
+			if c.State == "running" {
+				running++
+			}
+		}
+		if running == 0 {
+			out = append(out, Drift{Service: name, Container: deriveName(cs[0].Names), Kind: "stopped", Have: cs[0].State})
+		}
+		if svc.Deploy != nil && svc.Deploy.Replicas != nil && *svc.Deploy.Replicas != len(cs) {
+			out = append(out, Drift{Service: name, Kind: "replicas", Want: strconv.Itoa(*svc.Deploy.Replicas), Have: strconv.Itoa(len(cs))})
+		}
+		for _, c := range cs {
+			info, err := cli.ContainerInspect(ctx, c.ID)
+			if err != nil || info.Config == nil {
+				continue
+			}
+			out = append(out, diffService(name, deriveName(c.Names), svc, info)...)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Service != out[j].Service {
+			return out[i].Service < out[j].Service
+		}
+		return out[i].Container < out[j].Container
+	})
+	return out, nil
+}
+
+// diffService compares one container with its service definition.
+func diffService(service, name string, svc ComposeService, info container.InspectResponse) []Drift {
+	var out []Drift
+	if svc.Image != "" && normalizeRef(svc.Image) != normalizeRef(info.Config.Image) {
+		out = append(out, Drift{Service: service, Container: name, Kind: "image", Want: svc.Image, Have: info.Config.Image})
+	}
+	have := map[string]string{}
+	for _, kv := range info.Config.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		have[k] = v
+	}
+	keys := make([]string, 0, len(svc.Environment))
+	for k := range svc.Environment {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		want := svc.Environment[k]
+		if want == nil {
+			continue // passed through from the caller's environment
+		}
+		if v, ok := have[k]; !ok || v != *want {
+			d := Drift{Service: service, Container: name, Kind: "env", Want: k + "=" + *want}
+			if ok {
+				d.Have = k + "=" + v
+			}
+			out = append(out, d)
+		}
+	}
+	want := map[string]bool{}
+	for _, p := range svc.Ports {
+		if p.Published == "" {
+			continue // ephemeral host port
+		}
+		proto := p.Protocol
+		if proto == "" {
+			proto = "tcp"
+		}
+		want[fmt.Sprintf("%s:%d/%s", p.Published, p.Target, proto)] = true
+	}
+	got := map[string]bool{}
+	if info.HostConfig != nil {
+		for port, bindings := range info.HostConfig.PortBindings {
+			for _, b := range bindings {
+				if b.HostPort != "" {
+					got[fmt.Sprintf("%s:%s/%s", b.HostPort, port.Port(), port.Proto())] = true
+				}
+			}
+		}
+	}
+	if w, g := setString(want), setString(got); w != g {
+		out = append(out, Drift{Service: service, Container: name, Kind: "ports", Want: w, Have: g})
+	}
+	return out
+}
+
+func setString(set map[string]bool) string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// normalizeRef expands Docker Hub shorthands so "nginx", "nginx:latest" and
+// "docker.io/library/nginx:latest" compare equal.
+func normalizeRef(ref string) string {
+	ref = strings.TrimPrefix(ref, "docker.io/")
+	ref = strings.TrimPrefix(ref, "library/")
+	if !strings.Contains(ref, "@") && strings.LastIndex(ref, ":") <= strings.LastIndex(ref, "/") {
+		ref += ":latest"
+	}
+	return ref
+}
@@ -0,0 +1,186 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// RestartTracker follows container events. Start events flag containers
+// that (re)started more than Threshold times within Window as flapping; with
+// Run, die and OOM events are also kept as notices.
+type RestartTracker struct {
+	Threshold int
+	Window    time.Duration
+
+	mu      sync.Mutex
+	starts  map[string][]time.Time // by container ID, oldest first
+	notices []Notice               // newest last, at most maxNotices
+	since   time.Time              // notices only cover events after this
+}
+
+// maxNotices bounds the notices a tracker keeps.
+const maxNotices = 5
+
+// Notice records a container dying or being OOM-killed while watching.
+type Notice struct {
+	Time     time.Time
+	ID       string
+	Name     string
+	OOM      bool // the kernel OOM killer hit the container
+	Died     bool // the container stopped; ExitCode is valid
+	ExitCode int
+}
+
+// NewRestartTracker returns a tracker; call Run to start following events.
+func NewRestartTracker(threshold int, window time.Duration) *RestartTracker {
+	return &RestartTracker{Threshold: threshold, Window: window, starts: map[string][]time.Time{}, since: time.Now()}
+}
+
+// Run follows the daemon's event stream until ctx is done. It first replays
+// events from the last Window so flapping is detected right away, and
+// reconnects if the stream drops.
+func (t *RestartTracker) Run(ctx context.Context, cli *client.Client) {
+	since := time.Now().Add(-t.Window)
+	for ctx.Err() == nil {
+		msgs, errs := cli.Events(ctx, events.ListOptions{
+			Since:   strconv.FormatInt(since.Unix(), 10),
+			Filters: eventFilter(events.ActionStart, events.ActionDie, events.ActionOOM),
+		})
+	stream:
+		for {
+			select {
+			case m := <-msgs:
+				at := messageTime(m)
+				switch m.Action {
+				case events.ActionStart:
+					t.record(m.Actor.ID, at)
+				case events.ActionDie, events.ActionOOM:
+					t.notice(m, at)
+				}
+				since = at
+			case <-errs:
+				break stream
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Backfill loads start events from the last Window up to now and returns,
+// for one-shot runs that don't follow the live stream.
+func (t *RestartTracker) Backfill(ctx context.Context, cli *client.Client) error {
+	now := time.Now()
+	msgs, errs := cli.Events(ctx, events.ListOptions{
+		Since:   strconv.FormatInt(now.Add(-t.Window).Unix(), 10),
+		Until:   strconv.FormatInt(now.Unix(), 10),
+		Filters: eventFilter(events.ActionStart),
+	})
+	for {
+		select {
+		case m := <-msgs:
+			t.record(m.Actor.ID, messageTime(m))
+		case err := <-errs:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func eventFilter(actions ...events.Action) filters.Args {
+	args := filters.NewArgs(filters.Arg("type", string(events.ContainerEventType)))
+	for _, a := range actions {
+		args.Add("event", string(a))
+	}
+	return args
+}
+
+func messageTime(m events.Message) time.Time {
+	if m.TimeNano != 0 {
+		return time.Unix(0, m.TimeNano)
+	}
+	return time.Unix(m.Time, 0)
+}
+
+func (t *RestartTracker) record(id string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.starts[id] = append(t.starts[id], at)
+}
+
+// notice records a die or OOM event. The die that follows an OOM kill is
+// folded into the OOM notice so it reads as one incident with an exit code.
+func (t *RestartTracker) notice(m events.Message, at time.Time) {
+	if at.Before(t.since) {
+		return // replayed from before watching started
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m.Action == events.ActionDie {
+		code, _ := strconv.Atoi(m.Actor.Attributes["exitCode"])
+		for i := len(t.notices) - 1; i >= 0; i-- {
+			n := &t.notices[i]
+			if n.ID == m.Actor.ID && n.OOM && !n.Died && at.Sub(n.Time) < 10*time.Second {
+				n.Died, n.ExitCode = true, code
+				return
+			}
+		}
+		t.notices = append(t.notices, Notice{Time: at, ID: m.Actor.ID, Name: m.Actor.Attributes["name"], Died: true, ExitCode: code})
+	} else {
+		t.notices = append(t.notices, Notice{Time: at, ID: m.Actor.ID, Name: m.Actor.Attributes["name"], OOM: true})
+	}
+	if len(t.notices) > maxNotices {
+		t.notices = t.notices[len(t.notices)-maxNotices:]
+	}
+}
+
+// Notices returns the most recent die/OOM notices, oldest first.
+func (t *RestartTracker) Notices() []Notice {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Notice(nil), t.notices...)
+}
+
+// Apply sets RecentRestarts and Flapping on snaps from the events seen so far.
+// The first start inside the window is not counted as a restart.
+func (t *RestartTracker) Apply(snaps []ContainerSnapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cutoff := time.Now().Add(-t.Window)
+	for id, ts := range t.starts {
+		i := 0
+		for i < len(ts) && ts[i].Before(cutoff) {
+			i++
+		}
+		if i == len(ts) {
+			delete(t.starts, id)
+			continue
+		}
+		t.starts[id] = ts[i:]
+	}
+	for i := range snaps {
+		n := len(t.starts[snaps[i].ID]) - 1
+		if n < 0 {
+			n = 0
+		}
+		snaps[i].RecentRestarts = n
+		snaps[i].Flapping = n > t.Threshold
+	}
+}
@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/go-units"
+)
+
+// ExitCache keeps exited containers' exit code and finish time by ID, so
+// watch mode inspects each one once instead of on every refresh. An entry
+// is used only while the status the daemon lists still matches it, so a
+// container that ran and exited again is inspected anew.
+type ExitCache struct {
+	mu    sync.Mutex
+	exits map[string]exitInfo
+}
+
+type exitInfo struct {
+	code     int
+	finished time.Time // zero when the daemon reported none
+}
+
+// NewExitCache returns an empty cache.
+func NewExitCache() *ExitCache {
+	return &ExitCache{exits: map[string]exitInfo{}}
+}
+
+// get returns the cached exit of id if it agrees with listed, the status
+// from the container list (e.g. "Exited (137) 2 hours ago"). It is safe on
+// a nil cache.
+func (c *ExitCache) get(id, listed string) (exitInfo, bool) {
+	if c == nil {
+		return exitInfo{}, false
+	}
+	c.mu.Lock()
+	e, ok := c.exits[id]
+	c.mu.Unlock()
+	if !ok {
+		return exitInfo{}, false
+	}
+	prefix := fmt.Sprintf("Exited (%d)", e.code)
+	if e.finished.IsZero() {
+		return e, strings.HasPrefix(listed, prefix)
+	}
+	// The daemon words the age with go-units; near a unit boundary the two
+	// may differ, which only costs another inspect.
+	return e, listed == fmt.Sprintf("%s %s ago", prefix, units.HumanDuration(time.Now().UTC().Sub(e.finished)))
+}
+
+func (c *ExitCache) put(id string, e exitInfo) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.exits[id] = e
+	c.mu.Unlock()
+}
@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// ExposedPort is a container port published on the host, as listed by
+// `whale exposed`. HostNetwork rows have no ports: every port the container
+// listens on is on the host's interfaces.
+type ExposedPort struct {
+	Container     string `json:"container"`
+	ID            string `json:"id"`
+	HostIP        string `json:"host_ip,omitempty"`
+	HostPort      uint16 `json:"host_port,omitempty"`
+	ContainerPort uint16 `json:"container_port,omitempty"`
+	Proto         string `json:"proto,omitempty"`
+	HostNetwork   bool   `json:"host_network,omitempty"`
+	// Service names a database or admin service usually found on
+	// ContainerPort; Risky is set when such a port is bound on all
+	// interfaces.
+	Service string `json:"service,omitempty"`
+	Risky   bool   `json:"risky,omitempty"`
+}
+
+// Public reports whether the port is bound on all interfaces rather than a
+// specific address such as 127.0.0.1.
+func (p ExposedPort) Public() bool {
+	return p.HostNetwork || isWildcard(p.HostIP)
+}
+
+// sensitivePorts are container ports of services that should rarely be
+// reachable from outside the host.
+var sensitivePorts = map[uint16]string{
+	22:    "ssh",
+	1433:  "mssql",
+	1521:  "oracle",
+	2181:  "zookeeper",
+	2375:  "docker API (plain)",
+	2376:  "docker API",
+	2379:  "etcd",
+	3306:  "mysql",
+	3389:  "rdp",
+	5432:  "postgres",
+	5601:  "kibana",
+	5672:  "amqp",
+	5984:  "couchdb",
+	6379:  "redis",
+	6443:  "kubernetes API",
+	8086:  "influxdb",
+	8500:  "consul",
+	9042:  "cassandra",
+	9090:  "prometheus",
+	9092:  "kafka",
+	9200:  "elasticsearch",
+	10250: "kubelet",
+	11211: "memcached",
+	15672: "rabbitmq admin",
+	27017: "mongodb",
+}
+
+func isWildcard(ip string) bool {
+	return ip == "" || ip == "0.0.0.0" || ip == "::"
+}
+
+// CollectExposed lists every host-bound port of running containers, plus a
+// row per host-network container, ordered risky first, then by host port.
+func CollectExposed(ctx context.Context, cli *client.Client) ([]ExposedPort, error) {
+	containers, err := cli.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var out []ExposedPort
+	for _, c := range containers {
+		name := deriveName(c.Names)
+		if c.HostConfig.NetworkMode == "host" {
+			out = append(out, ExposedPort{Container: name, ID: c.ID, HostNetwork: true})
+			continue
+		}
+		for _, p := range c.Ports {
+			if p.PublicPort == 0 {
+				continue // exposed in the image but not published
+			}
+			e := ExposedPort{
+				Container:     name,
+				ID:            c.ID,
+				HostIP:        p.IP,
+				HostPort:      p.PublicPort,
+				ContainerPort: p.PrivatePort,
+				Proto:         p.Type,
+				Service:       sensitivePorts[p.PrivatePort],
+			}
+			e.Risky = e.Service != "" && isWildcard(e.HostIP)
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Risky != b.Risky {
+			return a.Risky
+		}
+		if a.HostPort != b.HostPort {
+			return a.HostPort < b.HostPort
+		}
+		if a.Container != b.Container {
+			return strings.ToLower(a.Container) < strings.ToLower(b.Container)
+		}
+		return a.HostIP < b.HostIP
+	})
+	return out, nil
+}
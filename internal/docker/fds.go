@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// CountFDs sets FDs and FDLimit on running snapshots from the process that
+// is closest to its open-files limit (RLIMIT_NOFILE is per process, so a
+// container total would not compare to anything). Processes are listed with
+// `docker top` and read from /proc, so this only works on Linux with whale
+// running on the Docker host with access to the containers' processes (in
+// practice as root); elsewhere the fields stay zero.
+func CountFDs(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot) {
+	forEachRunning(snaps, func(s *ContainerSnapshot) {
+		cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		top, err := cli.ContainerTop(cctx, s.ID, []string{"-o", "pid"})
+		if err != nil {
+			slog.Debug("listing processes failed", "container", s.Name, "err", err)
+			return
+		}
+		col := -1
+		for i, t := range top.Titles {
+			if strings.EqualFold(t, "PID") {
+				col = i
+			}
+		}
+		if col < 0 {
+			return
+		}
+		worst := -1.0
+		for _, p := range top.Processes {
+			if col >= len(p) {
+				continue
+			}
+			pid, err := strconv.Atoi(strings.TrimSpace(p[col]))
+			if err != nil {
+				continue
+			}
+			open, limit, ok := procFDs(pid)
+			if !ok {
+				continue
+			}
+			used := 0.0 // unlimited: never the closest to a limit
+			if limit > 0 {
+				used = float64(open) / float64(limit)
+			}
+			if used > worst {
+				worst = used
+				s.FDs, s.FDLimit = open, limit
+			}
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package docker
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procFDs counts the open file descriptors of host process pid and reads
+// its soft open-files limit (zero when unlimited).
+func procFDs(pid int) (open int, limit uint64, ok bool) {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	entries, err := os.ReadDir(filepath.Join(dir, "fd"))
+	if err != nil {
+		return 0, 0, false
+	}
+	f, err := os.Open(filepath.Join(dir, "limits"))
+	if err != nil {
+		return len(entries), 0, true
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// "Max open files            1024                 1048576              files"
+		rest, found := strings.CutPrefix(sc.Text(), "Max open files")
+		if !found {
+			continue
+		}
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			limit, _ = strconv.ParseUint(fields[0], 10, 64) // "unlimited" stays 0
+		}
+		break
+	}
+	return len(entries), limit, true
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package docker
+
+// procFDs needs procfs; outside Linux there is nothing to read.
+func procFDs(pid int) (open int, limit uint64, ok bool) {
+	return 0, 0, false
+}
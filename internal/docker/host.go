@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/client"
+)
+
+// HostInfo identifies the Docker host a collection came from.
+type HostInfo struct {
+	Hostname      string `json:"hostname"`
+	DaemonVersion string `json:"daemon_version"`
+	OS            string `json:"os,omitempty"` // e.g. "Ubuntu 24.04 LTS", "Docker Desktop"
+	CPUs          int    `json:"cpus"`
+	MemTotal      int64  `json:"mem_total"` // bytes
+	// VM is set when the daemon runs inside Docker Desktop's virtual
+	// machine. CPUs and MemTotal are then the VM's allocation, which is also
+	// the memory limit reported for containers without one.
+	VM bool `json:"vm,omitempty"`
+}
+
+// GetHostInfo asks the daemon for its host name, version and capacity.
+func GetHostInfo(ctx context.Context, cli *client.Client) (HostInfo, error) {
+	info, err := cli.Info(ctx)
+	if err != nil {
+		return HostInfo{}, err
+	}
+	return HostInfo{
+		Hostname:      info.Name,
+		DaemonVersion: info.ServerVersion,
+		OS:            info.OperatingSystem,
+		CPUs:          info.NCPU,
+		MemTotal:      info.MemTotal,
+		VM:            isDockerDesktop(info.OperatingSystem, info.Name),
+	}, nil
+}
+
+// isDockerDesktop recognises Docker Desktop's VM (macOS, Windows, and Linux
+// Desktop) from the daemon's reported OS and host name.
+func isDockerDesktop(os, name string) bool {
+	return strings.Contains(os, "Docker Desktop") || name == "docker-desktop"
+}
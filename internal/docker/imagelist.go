@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+)
+
+// ImageSummary is one local image for `whale images`.
+type ImageSummary struct {
+	ID      string    `json:"id"`
+	Tags    []string  `json:"tags,omitempty"`
+	Created time.Time `json:"created"`
+	Size    int64     `json:"size"`
+	// SharedSize is the part of Size in layers other images use too, so
+	// UniqueSize is what deleting this image alone would free. -1 when the
+	// daemon didn't compute it.
+	SharedSize int64 `json:"shared_size"`
+	UniqueSize int64 `json:"unique_size"`
+	Dangling   bool  `json:"dangling,omitempty"` // untagged
+	// Containers names every container (in any state) created from the
+	// image; Running counts those still running. LastUsed is now for a
+	// running container, otherwise when the last one stopped; it is only
+	// resolved for dangling images.
+	Containers []string  `json:"containers,omitempty"`
+	Running    int       `json:"running,omitempty"`
+	LastUsed   time.Time `json:"last_used,omitempty"`
+}
+
+// Reclaimable reports whether removing the image frees space right away:
+// it is untagged and no container, not even a stopped one, still uses it.
+func (i ImageSummary) Reclaimable() bool {
+	return i.Dangling && len(i.Containers) == 0
+}
+
+// CollectImages lists local images with the containers that use them,
+// ordered by the space deleting each would free. With danglingOnly only
+// untagged images are listed.
+func CollectImages(ctx context.Context, cli *client.Client, danglingOnly bool) ([]ImageSummary, error) {
+	opts := image.ListOptions{SharedSize: true}
+	if danglingOnly {
+		opts.Filters = filters.NewArgs(filters.Arg("dangling", "true"))
+	}
+	images, err := cli.ImageList(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+	byImage := map[string][]container.Summary{}
+	for _, c := range containers {
+		byImage[c.ImageID] = append(byImage[c.ImageID], c)
+	}
+	out := make([]ImageSummary, 0, len(images))
+	for _, img := range images {
+		s := ImageSummary{
+			ID:         img.ID,
+			Created:    time.Unix(img.Created, 0),
+			Size:       img.Size,
+			SharedSize: img.SharedSize,
+			UniqueSize: img.Size,
+			Dangling:   isDangling(img.RepoTags),
+		}
+		if img.SharedSize > 0 {
+			s.UniqueSize = img.Size - img.SharedSize
+		}
+		if !s.Dangling {
+			s.Tags = img.RepoTags
+		}
+		for _, c := range byImage[img.ID] {
+			s.Containers = append(s.Containers, deriveName(c.Names))
+			if c.State == "running" {
+				s.Running++
+			}
+		}
+		if s.Dangling {
+			s.LastUsed = lastUsed(ctx, cli, byImage[img.ID])
+		}
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UniqueSize > out[j].UniqueSize })
+	return out, nil
+}
+
+func isDangling(tags []string) bool {
+	for _, t := range tags {
+		if t != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+// lastUsed returns now if any of containers is running, otherwise the
+// latest time one of them stopped (zero if none ever ran).
+func lastUsed(ctx context.Context, cli *client.Client, containers []container.Summary) time.Time {
+	var last time.Time
+	for _, c := range containers {
+		if c.State == "running" {
+			return time.Now()
+		}
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil || info.State == nil {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, info.State.FinishedAt); err == nil && t.After(last) && t.Year() > 1 {
+			last = t
+		}
+	}
+	return last
+}
@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// ImageDigests resolves the registry digest and creation time of each
+// container's image and remembers them per image ID, so repeated
+// collections (watch mode) only inspect images they haven't seen before.
+type ImageDigests struct {
+	mu    sync.Mutex
+	cache map[string]imageMeta // by image ID
+}
+
+type imageMeta struct {
+	digest  string // "" for local-only images
+	created *time.Time
+}
+
+// NewImageDigests returns an empty digest cache.
+func NewImageDigests() *ImageDigests {
+	return &ImageDigests{cache: map[string]imageMeta{}}
+}
+
+// Apply sets ImageDigest and ImageCreated on snaps. Images without a repo digest (built
+// locally, never pushed or pulled) are left empty, as are images whose
+// inspection fails; the latter are retried on the next call.
+func (d *ImageDigests) Apply(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot) {
+	d.mu.Lock()
+	refs := map[string]string{} // unseen image ID -> a reference using it
+	for _, s := range snaps {
+		if _, ok := d.cache[s.ImageID]; !ok && s.ImageID != "" {
+			refs[s.ImageID] = s.Image
+		}
+	}
+	d.mu.Unlock()
+
+	ids := make([]string, 0, len(refs))
+	for id := range refs {
+		ids = append(ids, id)
+	}
+	idx := make([]int, len(ids))
+	for i := range idx {
+		idx[i] = i
+	}
+	sem := make(chan struct{}, 8)
+	runBounded(idx, func() { sem <- struct{}{} }, func(time.Duration, error) { <-sem }, func(_, i int) error {
+		cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		info, err := cli.ImageInspect(cctx, ids[i])
+		if err != nil {
+			return err
+		}
+		meta := imageMeta{digest: pickDigest(info.RepoDigests, refs[ids[i]])}
+		if t, err := time.Parse(time.RFC3339Nano, info.Created); err == nil {
+			meta.created = &t
+		}
+		d.mu.Lock()
+		d.cache[ids[i]] = meta
+		d.mu.Unlock()
+		return nil
+	}, nil)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range snaps {
+		meta := d.cache[snaps[i].ImageID]
+		snaps[i].ImageDigest, snaps[i].ImageCreated = meta.digest, meta.created
+	}
+}
+
+// pickDigest returns the digest from repoDigests ("repo@sha256:...") whose
+// repository matches ref, falling back to the first one.
+func pickDigest(repoDigests []string, ref string) string {
+	repo := ref
+	if i := strings.LastIndex(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	// Strip a tag, but not a registry port ("host:5000/app").
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	first := ""
+	for _, rd := range repoDigests {
+		name, digest, ok := strings.Cut(rd, "@")
+		if !ok {
+			continue
+		}
+		if name == repo || strings.HasSuffix(name, "/"+repo) {
+			return digest
+		}
+		if first == "" {
+			first = digest
+		}
+	}
+	return first
+}
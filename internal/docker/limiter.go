@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// AdaptiveLimiter bounds in-flight stats requests and tunes the bound with
+// AIMD: every window of successful fast calls raises the limit by one, while
+// a failed call or a run of slow ones halves it. It is safe for concurrent
+// use and is meant to be reused across collections so the learned limit
+// carries over.
+//
+// A non-streaming stats call waits for the daemon's next sample, so even a
+// healthy one takes a second or two. Calls count as slow only above twice
+// the fastest call seen, and never below the target.
+type AdaptiveLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	min      int
+	max      int
+	inflight int
+	okStreak int
+	// slowRun counts consecutive slow calls; fastest is the quickest
+	// successful call so far, the baseline for what counts as slow.
+	slowRun int
+	fastest time.Duration
+	target  time.Duration
+	lastCut time.Time
+}
+
+// SlowStatsCall is the default target for NewAdaptiveLimiter: comfortably
+// above a healthy non-streaming stats call.
+const SlowStatsCall = 2500 * time.Millisecond
+
+// slowCalls is how many consecutive slow calls it takes to halve the limit,
+// so a single straggler doesn't.
+const slowCalls = 3
+
+// NewAdaptiveLimiter returns a limiter starting at initial, kept within
+// [min, max]. target is the least latency that counts as slow, whatever the
+// measured baseline.
+func NewAdaptiveLimiter(initial, min, max int, target time.Duration) *AdaptiveLimiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	if initial < min {
+		initial = min
+	}
+	if initial > max {
+		initial = max
+	}
+	l := &AdaptiveLimiter{limit: initial, min: min, max: max, target: target}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// Acquire blocks until a request slot is available under the current limit.
+func (l *AdaptiveLimiter) Acquire() {
+	l.mu.Lock()
+	for l.inflight >= l.limit {
+		l.cond.Wait()
+	}
+	l.inflight++
+	l.mu.Unlock()
+}
+
+// Release frees a slot and feeds the call's outcome back into the limit.
+func (l *AdaptiveLimiter) Release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	if err == nil && (l.fastest == 0 || latency < l.fastest) {
+		l.fastest = latency
+	}
+	switch {
+	case err != nil:
+		l.cut(latency, err)
+	case latency > max(l.target, 2*l.fastest):
+		l.okStreak = 0
+		if l.slowRun++; l.slowRun >= slowCalls {
+			l.cut(latency, nil)
+		}
+	default:
+		l.slowRun = 0
+		l.okStreak++
+		if l.okStreak >= l.limit && l.limit < l.max {
+			l.limit++
+			l.okStreak = 0
+		}
+	}
+	l.cond.Broadcast()
+}
+
+// cut halves the limit, at most once per target window so a burst of calls
+// that were all started under the old limit does not collapse it to min.
+func (l *AdaptiveLimiter) cut(latency time.Duration, err error) {
+	l.okStreak, l.slowRun = 0, 0
+	if time.Since(l.lastCut) <= l.target {
+		return
+	}
+	l.limit = max(l.limit/2, l.min)
+	l.lastCut = time.Now()
+	slog.Debug("stats concurrency cut", "limit", l.limit, "latency", latency, "err", err)
+}
+
+// Limit reports the current concurrency limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
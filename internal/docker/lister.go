@@ -0,0 +1,96 @@
+package docker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// Lister is the container list shared by collectors: it makes at most one
+// ContainerList call per refresh and hands the result to every view that
+// asks: the stats and network collectors, name resolution, wait and the
+// bulk actions. A list of all containers also answers requests for only the
+// running ones.
+type Lister struct {
+	cli *client.Client
+
+	mu   sync.Mutex
+	key  string // the filters the cached list was made with
+	all  bool
+	list []container.Summary
+	at   time.Time
+}
+
+// listMaxAge bounds reuse for callers that don't mark refreshes with Next.
+const listMaxAge = time.Second
+
+// NewLister returns a Lister that lists containers through cli.
+func NewLister(cli *client.Client) *Lister {
+	return &Lister{cli: cli}
+}
+
+// Next starts a new refresh: the next List asks the daemon again. It is a
+// no-op on a nil Lister.
+func (l *Lister) Next() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.list = nil
+	l.mu.Unlock()
+}
+
+// List returns the containers matching f, stopped ones too when all is set,
+// reusing this refresh's list when it covers the request. Callers must not
+// modify the result.
+func (l *Lister) List(ctx context.Context, all bool, f filters.Args) ([]container.Summary, error) {
+	key, err := filters.ToJSON(f)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.list != nil && l.key == key && (l.all || !all) && time.Since(l.at) < listMaxAge {
+		if l.all == all {
+			return l.list, nil
+		}
+		return runningOnly(l.list), nil
+	}
+	start := time.Now()
+	list, err := l.cli.ContainerList(ctx, container.ListOptions{All: all, Filters: f})
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("listed containers", "count", len(list), "all", all, "took", time.Since(start))
+	if list == nil {
+		list = []container.Summary{} // cached: nil means no list yet
+	}
+	l.key, l.all, l.list, l.at = key, all, list, time.Now()
+	return list, nil
+}
+
+// runningOnly keeps the containers `docker ps` lists without -a: running,
+// paused and restarting ones.
+func runningOnly(list []container.Summary) []container.Summary {
+	out := make([]container.Summary, 0, len(list))
+	for _, c := range list {
+		switch c.State {
+		case "running", "paused", "restarting":
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// listContainers lists through l when it is set, else asks the daemon.
+func listContainers(ctx context.Context, cli *client.Client, l *Lister, all bool, f filters.Args) ([]container.Summary, error) {
+	if l != nil {
+		return l.List(ctx, all, f)
+	}
+	return cli.ContainerList(ctx, container.ListOptions{All: all, Filters: f})
+}
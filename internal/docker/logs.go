@@ -0,0 +1,231 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// maxLogBytes caps how much log output is kept per container and call; a
+// longer log keeps its newest maxLogBytes.
+const maxLogBytes = 4 * 1024 * 1024
+
+// ansiEscape matches terminal color/cursor sequences in log output.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+// ttys caches whether each container was created with a TTY, which decides
+// the log stream format. It can't change for a container's lifetime.
+var ttys sync.Map // container ID -> bool
+
+// ReadLogLines fetches a container's logs (stdout and stderr) and returns
+// them split into lines. Non-TTY containers use Docker's multiplexed stream
+// format, which is decoded. Logs longer than maxLogBytes keep their newest
+// lines, and truncated is set.
+func ReadLogLines(ctx context.Context, cli *client.Client, containerID string, opts container.LogsOptions) (lines []string, truncated bool, err error) {
+	tty, err := hasTTY(ctx, cli, containerID)
+	if err != nil {
+		return nil, false, err
+	}
+	opts.ShowStdout, opts.ShowStderr = true, true
+	rc, err := cli.ContainerLogs(ctx, containerID, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rc.Close()
+
+	tail := &tailWriter{max: maxLogBytes}
+	if tty {
+		_, err = io.Copy(tail, rc)
+	} else {
+		_, err = stdcopy.StdCopy(tail, tail, rc)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	text := string(tail.bytes())
+	if tail.dropped {
+		// The oldest kept line is most likely cut off.
+		_, text, _ = strings.Cut(text, "\n")
+	}
+	raw := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(raw) == 1 && raw[0] == "" {
+		return nil, tail.dropped, nil
+	}
+	return raw, tail.dropped, nil
+}
+
+// hasTTY reports whether the container was created with a TTY, from ttys
+// when it was asked before.
+func hasTTY(ctx context.Context, cli *client.Client, containerID string) (bool, error) {
+	if tty, ok := ttys.Load(containerID); ok {
+		return tty.(bool), nil
+	}
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return false, err
+	}
+	tty := info.Config != nil && info.Config.Tty
+	ttys.Store(containerID, tty)
+	return tty, nil
+}
+
+// tailWriter keeps the last max bytes written to it. It buffers up to twice
+// that before discarding, so long logs aren't shifted on every write.
+type tailWriter struct {
+	max     int
+	buf     []byte
+	dropped bool
+}
+
+// Write keeps p, dropping the oldest bytes once more than max are held.
+func (t *tailWriter) Write(p []byte) (int, error) {
+	if len(p) > t.max {
+		t.dropped = true
+		t.buf = append(t.buf[:0], p[len(p)-t.max:]...)
+		return len(p), nil
+	}
+	if len(t.buf)+len(p) > 2*t.max {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.max:]...)
+		t.dropped = true
+	}
+	t.buf = append(t.buf, p...)
+	return len(p), nil
+}
+
+// bytes returns the kept output.
+func (t *tailWriter) bytes() []byte {
+	if len(t.buf) > t.max {
+		t.dropped = true
+		return t.buf[len(t.buf)-t.max:]
+	}
+	return t.buf
+}
+
+// CountLogMatches sets LogErrors on running snapshots to the number of log
+// lines from the last window that match re, and LogErrorsTruncated when the
+// window held more than maxLogBytes, so the count covers only its newest
+// part. Containers whose logs cannot be read are left unset.
+func CountLogMatches(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot, window time.Duration, re *regexp.Regexp) {
+	since := strconv.FormatInt(time.Now().Add(-window).Unix(), 10)
+	forEachRunning(snaps, func(s *ContainerSnapshot) {
+		cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		lines, truncated, err := ReadLogLines(cctx, cli, s.ID, container.LogsOptions{Since: since})
+		if err != nil {
+			slog.Debug("reading logs failed", "container", s.Name, "err", err)
+			return
+		}
+		n := 0
+		for _, l := range lines {
+			if re.MatchString(l) {
+				n++
+			}
+		}
+		s.LogErrors = &n
+		s.LogErrorsTruncated = truncated
+	})
+}
+
+// PopulateLastLog sets LastLog to each container's most recent log line,
+// including stopped ones, where it often explains why the container exited.
+func PopulateLastLog(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot) {
+	forEach(snaps, func(s *ContainerSnapshot) bool { return s.Status != "ERROR" }, func(s *ContainerSnapshot) {
+		cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		lines, _, err := ReadLogLines(cctx, cli, s.ID, container.LogsOptions{Tail: "1"})
+		if err != nil || len(lines) == 0 {
+			return
+		}
+		line := ansiEscape.ReplaceAllString(lines[len(lines)-1], "")
+		s.LastLog = strings.TrimSpace(strings.Map(func(r rune) rune {
+			// Drop remaining control characters (e.g. \r) that would break the table.
+			if r < 0x20 || r == 0x7f {
+				return -1
+			}
+			return r
+		}, line))
+	})
+}
+
+// LogMatch is one log line that matched a search.
+type LogMatch struct {
+	Container string
+	Time      time.Time
+	Line      string
+}
+
+// GrepLogs searches the logs of snaps from the last since for lines matching
+// re, concurrently, and returns the matches in chronological order, with the
+// names of containers whose logs were longer than maxLogBytes and searched
+// only in their newest part. Containers whose logs cannot be read are
+// skipped; the first such error is returned alongside the matches found
+// elsewhere.
+func GrepLogs(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot, re *regexp.Regexp, since time.Duration) (matches []LogMatch, truncated []string, err error) {
+	opts := container.LogsOptions{Timestamps: true}
+	if since > 0 {
+		opts.Since = strconv.FormatInt(time.Now().Add(-since).Unix(), 10)
+	}
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	forEach(snaps, func(s *ContainerSnapshot) bool { return s.Status != "ERROR" }, func(s *ContainerSnapshot) {
+		lines, cut, err := ReadLogLines(ctx, cli, s.ID, opts)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", s.Name, err)
+			}
+			return
+		}
+		if cut {
+			truncated = append(truncated, s.Name)
+		}
+		for _, l := range lines {
+			ts, msg, _ := strings.Cut(l, " ")
+			if !re.MatchString(msg) {
+				continue
+			}
+			t, _ := time.Parse(time.RFC3339Nano, ts)
+			matches = append(matches, LogMatch{Container: s.Name, Time: t, Line: msg})
+		}
+	})
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Time.Before(matches[j].Time) })
+	sort.Strings(truncated)
+	return matches, truncated, firstErr
+}
+
+// forEachRunning runs fn for every running snapshot, a few at a time, so
+// log requests don't swamp the daemon.
+func forEachRunning(snaps []ContainerSnapshot, fn func(s *ContainerSnapshot)) {
+	forEach(snaps, func(s *ContainerSnapshot) bool {
+		return s.State == "running" && s.Status != "ERROR"
+	}, fn)
+}
+
+// forEach runs fn for the snapshots accepted by keep, 8 at a time.
+func forEach(snaps []ContainerSnapshot, keep func(s *ContainerSnapshot) bool, fn func(s *ContainerSnapshot)) {
+	idx := make([]int, 0, len(snaps))
+	for i := range snaps {
+		if keep(&snaps[i]) {
+			idx = append(idx, i)
+		}
+	}
+	sem := make(chan struct{}, 8)
+	runBounded(idx, func() { sem <- struct{}{} }, func(time.Duration, error) { <-sem }, func(_, i int) error {
+		fn(&snaps[i])
+		return nil
+	}, nil)
+}
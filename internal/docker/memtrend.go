@@ -0,0 +1,90 @@
+package docker
+
+import "time"
+
+// MemTrend remembers recent memory readings per container and, for those
+// growing steadily, estimates when usage will reach the memory limit. It is
+// an early warning for leaks that end in an OOM kill.
+type MemTrend struct {
+	// Window is how far back readings are fitted; Samples is the minimum
+	// number of readings needed before an estimate is made.
+	Window  time.Duration
+	Samples int
+	// Horizon drops estimates further out than this; a slow drift over a
+	// few minutes says little about next week.
+	Horizon time.Duration
+	hist    map[string][]memSample
+}
+
+type memSample struct {
+	at    time.Time
+	bytes float64
+}
+
+// NewMemTrend returns a tracker fitting readings from the last window.
+func NewMemTrend(window time.Duration) *MemTrend {
+	return &MemTrend{Window: window, Samples: 5, Horizon: 7 * 24 * time.Hour, hist: map[string][]memSample{}}
+}
+
+// Apply records the current readings and sets MemFullIn on snaps whose
+// usage trends upward towards a limit.
+func (t *MemTrend) Apply(snaps []ContainerSnapshot) {
+	seen := make(map[string]struct{}, len(snaps))
+	for i := range snaps {
+		s := &snaps[i]
+		seen[s.ID] = struct{}{}
+		if s.Stale || s.StatsUnavailable || s.MemUsage == 0 {
+			// No stats this round; keep the history but don't extend it.
+			continue
+		}
+		at := s.CollectedAt
+		if at.IsZero() {
+			at = time.Now()
+		}
+		h := append(t.hist[s.ID], memSample{at, float64(s.MemUsage)})
+		for len(h) > 0 && at.Sub(h[0].at) > t.Window {
+			h = h[1:]
+		}
+		t.hist[s.ID] = h
+		s.MemFullIn = t.estimate(h, s.MemUsage, s.MemLimit)
+	}
+	for id := range t.hist {
+		if _, ok := seen[id]; !ok {
+			delete(t.hist, id)
+		}
+	}
+}
+
+// estimate fits a least-squares line through h and returns the time until
+// usage reaches limit at that rate, or zero when there is no upward trend.
+func (t *MemTrend) estimate(h []memSample, usage, limit uint64) time.Duration {
+	if len(h) < t.Samples || limit == 0 || usage >= limit {
+		return 0
+	}
+	// Usage that ends lower than it started is not a leak, whatever the fit.
+	if h[len(h)-1].bytes <= h[0].bytes {
+		return 0
+	}
+	var sx, sy, sxx, sxy float64
+	n := float64(len(h))
+	for _, p := range h {
+		x := p.at.Sub(h[0].at).Seconds()
+		sx += x
+		sy += p.bytes
+		sxx += x * x
+		sxy += x * p.bytes
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return 0
+	}
+	slope := (n*sxy - sx*sy) / den // bytes per second
+	if slope <= 0 {
+		return 0
+	}
+	eta := time.Duration(float64(limit-usage) / slope * float64(time.Second))
+	if eta <= 0 || eta > t.Horizon {
+		return 0
+	}
+	return eta
+}
@@ -0,0 +1,137 @@
+package docker
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// Mount is one volume, bind mount or tmpfs in a container.
+type Mount struct {
+	Type        string `json:"type"`           // bind, volume, tmpfs, npipe...
+	Name        string `json:"name,omitempty"` // volume name, for volumes
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination"`
+	RW          bool   `json:"rw"`
+	// Warning explains why a bind mount of a sensitive host path is risky;
+	// empty for everything else (see SensitiveMount).
+	Warning string `json:"warning,omitempty"`
+	// Size is the data in a named volume or tmpfs, when measured (see
+	// VolumeSizes and FillTmpfsUsage). Limit is a tmpfs's capacity.
+	Size  *int64 `json:"size,omitempty"`
+	Limit int64  `json:"limit,omitempty"`
+}
+
+// ContainerMounts is a container with all of its mounts, for `whale mounts`.
+type ContainerMounts struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Status string  `json:"status"`
+	State  string  `json:"state,omitempty"`
+	Mounts []Mount `json:"mounts"`
+}
+
+// VolumeBytes returns the total measured size of the container's named
+// volumes and whether any were measured.
+func (c ContainerMounts) VolumeBytes() (int64, bool) {
+	var total int64
+	measured := false
+	for _, m := range c.Mounts {
+		if m.Size != nil && m.Type == "volume" {
+			total += *m.Size
+			measured = true
+		}
+	}
+	return total, measured
+}
+
+// mountsOf converts the list API's mount points, sorted by destination.
+func mountsOf(mps []container.MountPoint) []Mount {
+	if len(mps) == 0 {
+		return nil
+	}
+	out := make([]Mount, 0, len(mps))
+	for _, m := range mps {
+		out = append(out, Mount{
+			Type:        string(m.Type),
+			Name:        m.Name,
+			Source:      m.Source,
+			Destination: m.Destination,
+			RW:          m.RW,
+			Warning:     SensitiveMount(string(m.Type), m.Source),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Destination < out[j].Destination })
+	return out
+}
+
+// sensitivePaths are host paths that give a container control over, or a
+// view into, the host when bind-mounted. Paths below them match too.
+var sensitivePaths = []struct{ path, why string }{
+	{"/var/run/docker.sock", "Docker socket: full control of the host"},
+	{"/run/docker.sock", "Docker socket: full control of the host"},
+	{"/etc", "host configuration"},
+	{"/root", "root's home directory"},
+	{"/home", "home directories"},
+	{"/Users", "home directories"},
+	{"/proc", "host process information"},
+	{"/sys", "host kernel settings"},
+	{"/dev", "host devices"},
+	{"/boot", "host boot files"},
+	{"/var/lib/docker", "Docker's data root"},
+}
+
+// SensitiveMount returns why a mount of source is a risk, or "" if it is
+// not a bind mount of a sensitive host path.
+func SensitiveMount(mountType, source string) string {
+	if mountType != "bind" || source == "" {
+		return ""
+	}
+	p := path.Clean(source)
+	if p == "/" {
+		return "host root filesystem"
+	}
+	for _, s := range sensitivePaths {
+		if p == s.path || strings.HasPrefix(p, s.path+"/") {
+			return s.why
+		}
+	}
+	return ""
+}
+
+// Flagged reports whether any of the container's mounts carry a warning.
+func (c ContainerMounts) Flagged() bool {
+	for _, m := range c.Mounts {
+		if m.Warning != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectMounts lists every container's mounts, sorted by container name.
+// Containers without mounts are included with an empty list.
+func CollectMounts(ctx context.Context, cli *client.Client, includeAll bool) ([]ContainerMounts, error) {
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: includeAll})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ContainerMounts, 0, len(containers))
+	for _, c := range containers {
+		out = append(out, ContainerMounts{
+			ID:     c.ID,
+			Name:   deriveName(c.Names),
+			Status: deriveStatus(c.State, c.Status),
+			State:  c.State,
+			Mounts: mountsOf(c.Mounts),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+	})
+	return out, nil
+}
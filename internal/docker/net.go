@@ -2,27 +2,32 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 )
 
-// ContainerNetInfo is a minimal view used for network grouping.
+// ContainerNetInfo is a minimal view used for network grouping. Service is
+// set for Swarm services (see AddSwarmServices), whose Status summarises
+// their tasks and ingress ports.
 type ContainerNetInfo struct {
 	ID       string
 	Name     string
 	Status   string
 	Networks []string
+	Service  bool
 }
 
-// CollectNetworks groups containers by the networks they are connected to.
-// Containers with no networks are placed under the "(none)" group.
-func CollectNetworks(ctx context.Context, cli *client.Client, includeAll bool) (map[string][]ContainerNetInfo, error) {
-	listOpts := container.ListOptions{All: includeAll}
-	containers, err := cli.ContainerList(ctx, listOpts)
+// CollectNetworks groups containers, as listed by l, by the networks they
+// are connected to. Containers with no networks are placed under the
+// "(none)" group.
+func CollectNetworks(ctx context.Context, l *Lister, includeAll bool) (map[string][]ContainerNetInfo, error) {
+	containers, err := l.List(ctx, includeAll, filters.Args{})
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +56,78 @@ func CollectNetworks(ctx context.Context, cli *client.Client, includeAll bool) (
 	return groups, nil
 }
 
+// MembershipChange is a container joining or leaving a network between two
+// network-watch refreshes. Gone is set when the container itself is no
+// longer listed (stopped or removed) rather than just disconnected.
+type MembershipChange struct {
+	Time      time.Time
+	Network   string
+	Container string
+	Connected bool
+	Gone      bool
+}
+
+// MembershipLog compares successive CollectNetworks results and keeps the
+// most recent connects and disconnects, which are easy to miss across full
+// redraws.
+type MembershipLog struct {
+	prev    map[string]map[string]string // network -> container ID -> name
+	changes []MembershipChange           // newest last, at most maxNotices
+}
+
+// Apply records the changes since the previous call. The first call only
+// takes the baseline.
+func (l *MembershipLog) Apply(groups map[string][]ContainerNetInfo) {
+	now := time.Now()
+	cur := make(map[string]map[string]string, len(groups))
+	listed := map[string]bool{}
+	for n, members := range groups {
+		for _, c := range members {
+			listed[c.ID] = true
+			if n == "(none)" {
+				continue
+			}
+			if cur[n] == nil {
+				cur[n] = map[string]string{}
+			}
+			cur[n][c.ID] = c.Name
+		}
+	}
+	if l.prev != nil {
+		var added []MembershipChange
+		for n, members := range cur {
+			for id, name := range members {
+				if _, ok := l.prev[n][id]; !ok {
+					added = append(added, MembershipChange{Time: now, Network: n, Container: name, Connected: true})
+				}
+			}
+		}
+		for n, members := range l.prev {
+			for id, name := range members {
+				if _, ok := cur[n][id]; !ok {
+					added = append(added, MembershipChange{Time: now, Network: n, Container: name, Gone: !listed[id]})
+				}
+			}
+		}
+		sort.Slice(added, func(i, j int) bool {
+			if added[i].Container != added[j].Container {
+				return added[i].Container < added[j].Container
+			}
+			return added[i].Network < added[j].Network
+		})
+		l.changes = append(l.changes, added...)
+		if len(l.changes) > maxNotices {
+			l.changes = l.changes[len(l.changes)-maxNotices:]
+		}
+	}
+	l.prev = cur
+}
+
+// Changes returns the recent changes, oldest first.
+func (l *MembershipLog) Changes() []MembershipChange {
+	return l.changes
+}
+
 func extractNetworkNames(ns *types.SummaryNetworkSettings) []string {
 	if ns == nil || ns.Networks == nil {
 		return nil
@@ -62,3 +139,67 @@ func extractNetworkNames(ns *types.SummaryNetworkSettings) []string {
 	sort.Strings(names)
 	return names
 }
+
+// NetworkAttachment is one network a container is connected to.
+type NetworkAttachment struct {
+	Name string `json:"name"`
+	IP   string `json:"ip,omitempty"`
+	IPv6 string `json:"ipv6,omitempty"`
+	MAC  string `json:"mac,omitempty"`
+}
+
+// networkAttachments lists the container's networks, sorted by name.
+func networkAttachments(ns *types.SummaryNetworkSettings) []NetworkAttachment {
+	names := extractNetworkNames(ns)
+	if len(names) == 0 {
+		return nil
+	}
+	out := make([]NetworkAttachment, 0, len(names))
+	for _, n := range names {
+		a := NetworkAttachment{Name: n}
+		if ep := ns.Networks[n]; ep != nil {
+			a.IP, a.IPv6, a.MAC = ep.IPAddress, ep.GlobalIPv6Address, ep.MacAddress
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// FillHostNetwork gives host-network containers, whose stats carry no
+// network counters, the host's own interface totals. They are host-wide, not
+// per container; rows stay marked HostNetwork so this is visible.
+func FillHostNetwork(snaps []ContainerSnapshot) {
+	rx, tx, ok := HostNetDev()
+	if !ok {
+		return
+	}
+	for i := range snaps {
+		s := &snaps[i]
+		if s.HostNetwork && s.State == "running" && s.NetRx == 0 && s.NetTx == 0 {
+			s.NetRx, s.NetTx = rx, tx
+		}
+	}
+}
+
+// ContainerIP returns the container's IP on its first network (by name).
+// Host-network containers have no IP of their own and yield an error.
+func ContainerIP(ctx context.Context, cli *client.Client, id string) (string, error) {
+	info, err := cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if info.NetworkSettings == nil || len(info.NetworkSettings.Networks) == 0 {
+		return "", errors.New("container has no networks")
+	}
+	names := make([]string, 0, len(info.NetworkSettings.Networks))
+	for n := range info.NetworkSettings.Networks {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if ep := info.NetworkSettings.Networks[n]; ep != nil && ep.IPAddress != "" {
+			return ep.IPAddress, nil
+		}
+	}
+	return "", errors.New("container has no IP address (host network or none?)")
+}
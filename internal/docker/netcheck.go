@@ -0,0 +1,227 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// SharedNetwork is a network two containers are both attached to, with each
+// one's address on it.
+type SharedNetwork struct {
+	Name string
+	IPA  string
+	IPB  string
+}
+
+// SharedNetworks lists the networks a and b are both attached to, by name.
+func SharedNetworks(a, b ContainerSnapshot) []SharedNetwork {
+	var out []SharedNetwork
+	for _, na := range a.Networks {
+		for _, nb := range b.Networks {
+			if na.Name == nb.Name {
+				out = append(out, SharedNetwork{Name: na.Name, IPA: na.IP, IPB: nb.IP})
+			}
+		}
+	}
+	return out
+}
+
+// ExecOutput runs cmd in a running container without a terminal and returns
+// its combined output and exit code.
+func ExecOutput(ctx context.Context, cli *client.Client, id string, cmd []string) (string, int, error) {
+	created, err := cli.ContainerExecCreate(ctx, id, container.ExecOptions{Cmd: cmd, AttachStdout: true, AttachStderr: true})
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Close()
+	var out bytes.Buffer
+	if _, err := stdcopy.StdCopy(&out, &out, resp.Reader); err != nil {
+		return "", 0, err
+	}
+	info, err := cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return "", 0, err
+	}
+	return strings.TrimSpace(out.String()), info.ExitCode, nil
+}
+
+// ProbeResult is the outcome of an active reachability probe.
+type ProbeResult struct {
+	Method string // the command that ran, e.g. "ping -c 1 -W 2 172.18.0.2"
+	OK     bool
+	Output string
+}
+
+// probeTimeout bounds each probe command.
+const probeTimeout = 2 * time.Second
+
+// Probe checks from inside container id whether ip (and, when port > 0,
+// that TCP port) can be reached. Commands are tried in order until one that
+// exists in the image runs; an error means none did.
+func Probe(ctx context.Context, cli *client.Client, id, ip string, port int) (ProbeResult, error) {
+	secs := strconv.Itoa(int(probeTimeout.Seconds()))
+	var candidates [][]string
+	if port > 0 {
+		p := strconv.Itoa(port)
+		candidates = [][]string{
+			{"nc", "-z", "-w", secs, ip, p},
+			{"bash", "-c", fmt.Sprintf("timeout %s bash -c '</dev/tcp/%s/%s'", secs, ip, p)},
+		}
+	} else {
+		candidates = [][]string{
+			{"ping", "-c", "1", "-W", secs, ip},
+		}
+	}
+	for _, cmd := range candidates {
+		out, code, err := ExecOutput(ctx, cli, id, cmd)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return ProbeResult{}, err
+		}
+		if code == 126 || code == 127 {
+			continue // tool missing from the image
+		}
+		return ProbeResult{Method: strings.Join(cmd, " "), OK: code == 0, Output: out}, nil
+	}
+	tools := "ping"
+	if port > 0 {
+		tools = "nc or bash"
+	}
+	return ProbeResult{}, fmt.Errorf("no probe tool in the container (tried %s)", tools)
+}
+
+// isNotFound reports a daemon error for a command missing from the image,
+// which some runtimes return from exec instead of exit code 127.
+func isNotFound(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "executable file not found") || strings.Contains(msg, "no such file or directory")
+}
+
+// Lookup is what a DNS lookup inside a container returned.
+type Lookup struct {
+	Method    string   // the command that ran, e.g. "getent hosts db"
+	Resolvers []string // nameservers from the container's /etc/resolv.conf
+	Addresses []string
+	Output    string
+}
+
+// LookupIn resolves name from inside container id, as the container's own
+// processes would, trying getent, nslookup, dig and host until one exists in
+// the image. A name that does not resolve yields no addresses, not an error.
+func LookupIn(ctx context.Context, cli *client.Client, id, name string) (Lookup, error) {
+	var l Lookup
+	if conf, code, err := ExecOutput(ctx, cli, id, []string{"cat", "/etc/resolv.conf"}); err == nil && code == 0 {
+		for _, line := range strings.Split(conf, "\n") {
+			if f := strings.Fields(line); len(f) >= 2 && f[0] == "nameserver" {
+				l.Resolvers = append(l.Resolvers, f[1])
+			}
+		}
+	}
+	candidates := []struct {
+		cmd   []string
+		parse func(string) []string
+	}{
+		{[]string{"getent", "hosts", name}, parseGetent},
+		{[]string{"nslookup", name}, parseNslookup},
+		{[]string{"dig", "+short", name}, parseDig},
+		{[]string{"host", name}, parseHost},
+	}
+	for _, c := range candidates {
+		out, code, err := ExecOutput(ctx, cli, id, c.cmd)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return Lookup{}, err
+		}
+		if code == 126 || code == 127 {
+			continue
+		}
+		l.Method, l.Output, l.Addresses = strings.Join(c.cmd, " "), out, c.parse(out)
+		return l, nil
+	}
+	return Lookup{}, fmt.Errorf("no DNS tool in the container (tried getent, nslookup, dig, host)")
+}
+
+// parseGetent reads "ADDR NAME [ALIASES]" lines.
+func parseGetent(out string) []string {
+	var addrs []string
+	for _, line := range strings.Split(out, "\n") {
+		if f := strings.Fields(line); len(f) >= 2 && isIP(f[0]) {
+			addrs = appendUnique(addrs, f[0])
+		}
+	}
+	return addrs
+}
+
+// parseNslookup reads the "Address:" lines that follow the first "Name:"
+// line; the ones before it describe the DNS server.
+func parseNslookup(out string) []string {
+	var addrs []string
+	answer := false
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Name:"):
+			answer = true
+		case answer && strings.HasPrefix(line, "Address"):
+			// "Address: 1.2.3.4" or busybox's older "Address 1: 1.2.3.4 name"
+			if _, v, ok := strings.Cut(line, ":"); ok {
+				if f := strings.Fields(v); len(f) > 0 && isIP(f[0]) {
+					addrs = appendUnique(addrs, f[0])
+				}
+			}
+		}
+	}
+	return addrs
+}
+
+// parseDig reads `dig +short` output, skipping CNAME targets.
+func parseDig(out string) []string {
+	var addrs []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); isIP(line) {
+			addrs = appendUnique(addrs, line)
+		}
+	}
+	return addrs
+}
+
+// parseHost reads "NAME has address ADDR" lines.
+func parseHost(out string) []string {
+	var addrs []string
+	for _, line := range strings.Split(out, "\n") {
+		if f := strings.Fields(line); len(f) >= 4 && strings.Contains(line, " address ") && isIP(f[len(f)-1]) {
+			addrs = appendUnique(addrs, f[len(f)-1])
+		}
+	}
+	return addrs
+}
+
+func isIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,200 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// NetworkDetail is one network as shown by `whale net <network>`.
+type NetworkDetail struct {
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	Driver   string `json:"driver"`
+	Scope    string `json:"scope"`
+	Internal bool   `json:"internal,omitempty"`
+	// Parent and Mode are the host interface and mode of a macvlan or
+	// ipvlan network.
+	Parent string `json:"parent,omitempty"`
+	Mode   string `json:"mode,omitempty"`
+	// MTU is the network's configured MTU; empty means the daemon default.
+	MTU string `json:"mtu,omitempty"`
+	// Options holds the remaining driver options, e.g.
+	// com.docker.network.bridge.name.
+	Options   map[string]string `json:"options,omitempty"`
+	Subnets   []Subnet          `json:"subnets,omitempty"`
+	Endpoints []Endpoint        `json:"endpoints"`
+}
+
+// Subnet is one IPAM pool of a network.
+type Subnet struct {
+	Subnet  string `json:"subnet"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// Endpoint is a container's attachment to a network. DuplicateMAC is set
+// when another endpoint on the same network has the same MAC address, which
+// breaks ARP (typically a hand-assigned MAC on macvlan).
+type Endpoint struct {
+	Container    string `json:"container"`
+	ContainerID  string `json:"container_id"`
+	EndpointID   string `json:"endpoint_id"`
+	MAC          string `json:"mac,omitempty"`
+	IPv4         string `json:"ipv4,omitempty"`
+	IPv6         string `json:"ipv6,omitempty"`
+	DuplicateMAC bool   `json:"duplicate_mac,omitempty"`
+}
+
+// mtuOption is the driver option carrying a network's MTU.
+const mtuOption = "com.docker.network.driver.mtu"
+
+// shownOptions are driver options NetworkDetail already shows in their own
+// fields.
+var shownOptions = map[string]bool{
+	mtuOption:      true,
+	"parent":       true,
+	"macvlan_mode": true,
+	"ipvlan_mode":  true,
+	"ipvlan_flag":  true,
+}
+
+// InspectNetwork returns the details and endpoints of the network with the
+// given name or ID, endpoints sorted by container name.
+func InspectNetwork(ctx context.Context, cli *client.Client, name string) (NetworkDetail, error) {
+	n, err := cli.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err != nil {
+		return NetworkDetail{}, err
+	}
+	d := NetworkDetail{Name: n.Name, ID: n.ID, Driver: n.Driver, Scope: n.Scope, Internal: n.Internal}
+	d.Parent, d.Mode = parentInterface(n)
+	d.MTU = n.Options[mtuOption]
+	for k, v := range n.Options {
+		if shownOptions[k] {
+			continue
+		}
+		if d.Options == nil {
+			d.Options = map[string]string{}
+		}
+		d.Options[k] = v
+	}
+	for _, c := range n.IPAM.Config {
+		d.Subnets = append(d.Subnets, Subnet{Subnet: c.Subnet, Gateway: c.Gateway})
+	}
+	macs := map[string]int{}
+	for id, ep := range n.Containers {
+		d.Endpoints = append(d.Endpoints, Endpoint{
+			Container:   ep.Name,
+			ContainerID: id,
+			EndpointID:  ep.EndpointID,
+			MAC:         ep.MacAddress,
+			IPv4:        ep.IPv4Address,
+			IPv6:        ep.IPv6Address,
+		})
+		if ep.MacAddress != "" {
+			macs[strings.ToLower(ep.MacAddress)]++
+		}
+	}
+	for i := range d.Endpoints {
+		d.Endpoints[i].DuplicateMAC = macs[strings.ToLower(d.Endpoints[i].MAC)] > 1
+	}
+	sort.Slice(d.Endpoints, func(i, j int) bool {
+		return strings.ToLower(d.Endpoints[i].Container) < strings.ToLower(d.Endpoints[j].Container)
+	})
+	return d, nil
+}
+
+// networkConcurrency bounds parallel network inspects, as for stats.
+const networkConcurrency = 8
+
+// InspectNetworks inspects the networks with the given names or IDs
+// concurrently, a few at a time, and returns them in the same order. With
+// no names it inspects every network, sorted by name. The first failure is
+// returned along with the networks that could be inspected.
+func InspectNetworks(ctx context.Context, cli *client.Client, names []string) ([]NetworkDetail, error) {
+	if len(names) == 0 {
+		list, err := cli.NetworkList(ctx, network.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		for _, n := range list {
+			names = append(names, n.ID)
+		}
+	}
+	details := make([]NetworkDetail, len(names))
+	errs := make([]error, len(names))
+	idx := make([]int, len(names))
+	for i := range idx {
+		idx[i] = i
+	}
+	sem := make(chan struct{}, networkConcurrency)
+	runBounded(idx, func() { sem <- struct{}{} }, func(time.Duration, error) { <-sem }, func(_, i int) error {
+		details[i], errs[i] = InspectNetwork(ctx, cli, names[i])
+		return errs[i]
+	}, nil)
+	out := details[:0]
+	var firstErr error
+	for i, d := range details {
+		if errs[i] != nil {
+			slog.Debug("inspecting network failed", "network", names[i], "err", errs[i])
+			if firstErr == nil {
+				firstErr = fmt.Errorf("network %s: %w", names[i], errs[i])
+			}
+			continue
+		}
+		out = append(out, d)
+	}
+	return out, firstErr
+}
+
+// parentInterface returns the host interface and mode of a macvlan or
+// ipvlan network, filling in the drivers' defaults when no mode was set.
+// Other drivers yield empty strings.
+func parentInterface(n network.Inspect) (parent, mode string) {
+	switch n.Driver {
+	case "macvlan":
+		mode = n.Options["macvlan_mode"]
+		if mode == "" {
+			mode = "bridge"
+		}
+	case "ipvlan":
+		mode = n.Options["ipvlan_mode"]
+		if mode == "" {
+			mode = "l2"
+		}
+		if flag := n.Options["ipvlan_flag"]; flag != "" && flag != "bridge" {
+			mode += " " + flag
+		}
+	default:
+		return "", ""
+	}
+	parent = n.Options["parent"]
+	if parent == "" {
+		// Without a parent Docker creates a dummy link: no outside traffic.
+		parent = "(none, internal dummy link)"
+	}
+	return parent, mode
+}
+
+// NetworkParents maps each macvlan and ipvlan network to a short note on
+// its parent interface and mode, e.g. "macvlan on eth0, bridge mode", for
+// the grouped network view.
+func NetworkParents(ctx context.Context, cli *client.Client) (map[string]string, error) {
+	list, err := cli.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	notes := map[string]string{}
+	for _, n := range list {
+		if parent, mode := parentInterface(n); parent != "" {
+			notes[n.Name] = fmt.Sprintf("%s on %s, %s mode", n.Driver, parent, mode)
+		}
+	}
+	return notes, nil
+}
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// Outdated is the registry check for one container's image.
+type Outdated struct {
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	Running string `json:"running_digest,omitempty"` // digest of the local image
+	Latest  string `json:"latest_digest,omitempty"`  // digest the tag points at now
+	Stale   bool   `json:"stale"`
+	// Skipped explains why no comparison was made (pinned by digest,
+	// built locally, registry error...).
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// CheckOutdated asks the registry, through the daemon, which digest each
+// container's image tag currently points at and compares it with the digest
+// the container runs. snaps need ImageDigest set (see ImageDigests).
+// Each tag is looked up once, with credentials from the Docker CLI config.
+func CheckOutdated(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot) []Outdated {
+	cfg := loadDockerConfig()
+	type lookup struct {
+		digest string
+		err    error
+	}
+	refs := map[string]*lookup{}
+	var order []string
+	out := make([]Outdated, len(snaps))
+	for i, s := range snaps {
+		out[i] = Outdated{Name: s.Name, Image: s.Image, Running: s.ImageDigest}
+		switch {
+		case strings.Contains(s.Image, "@"):
+			out[i].Skipped = "pinned by digest"
+		case strings.HasPrefix(s.Image, "sha256:"):
+			out[i].Skipped = "created from an image ID"
+		case s.ImageDigest == "":
+			out[i].Skipped = "local image (no registry digest)"
+		default:
+			if refs[s.Image] == nil {
+				refs[s.Image] = &lookup{}
+				order = append(order, s.Image)
+			}
+		}
+	}
+	idx := make([]int, len(order))
+	for i := range idx {
+		idx[i] = i
+	}
+	sem := make(chan struct{}, 4)
+	runBounded(idx, func() { sem <- struct{}{} }, func(time.Duration, error) { <-sem }, func(_, i int) error {
+		cctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		ref := order[i]
+		info, err := cli.DistributionInspect(cctx, ref, registryAuth(cfg, ref))
+		refs[ref].digest, refs[ref].err = string(info.Descriptor.Digest), err
+		return err
+	}, nil)
+	for i := range out {
+		l := refs[out[i].Image]
+		if out[i].Skipped != "" || l == nil {
+			continue
+		}
+		if l.err != nil {
+			out[i].Skipped = "registry: " + l.err.Error()
+			continue
+		}
+		out[i].Latest = l.digest
+		out[i].Stale = l.digest != out[i].Running
+	}
+	return out
+}
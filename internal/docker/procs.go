@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// CountZombies sets Zombies on running snapshots to the number of defunct
+// (state Z) processes reported by `docker top`. Containers whose process
+// list cannot be read are left at zero.
+func CountZombies(ctx context.Context, cli *client.Client, snaps []ContainerSnapshot) {
+	forEachRunning(snaps, func(s *ContainerSnapshot) {
+		cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		top, err := cli.ContainerTop(cctx, s.ID, []string{"-o", "pid,stat"})
+		if err != nil {
+			slog.Debug("listing processes failed", "container", s.Name, "err", err)
+			return
+		}
+		col := -1
+		for i, t := range top.Titles {
+			if strings.EqualFold(t, "STAT") {
+				col = i
+			}
+		}
+		if col < 0 {
+			return
+		}
+		n := 0
+		for _, p := range top.Processes {
+			if col < len(p) && strings.HasPrefix(p[col], "Z") {
+				n++
+			}
+		}
+		s.Zombies = n
+	})
+}
+
+// PIDTrend remembers recent PID counts per container to spot steady growth,
+// the usual sign of a process or thread leak that will eventually hit the
+// container's PIDs limit.
+type PIDTrend struct {
+	// Samples is how many consecutive non-decreasing samples (with an
+	// overall increase) count as growth.
+	Samples int
+	hist    map[string][]int
+}
+
+// NewPIDTrend returns a tracker that flags growth over samples samples.
+func NewPIDTrend(samples int) *PIDTrend {
+	if samples < 2 {
+		samples = 2
+	}
+	return &PIDTrend{Samples: samples, hist: map[string][]int{}}
+}
+
+// Apply records the current PID counts and sets PIDsGrowing on snaps.
+func (t *PIDTrend) Apply(snaps []ContainerSnapshot) {
+	seen := make(map[string]struct{}, len(snaps))
+	for i := range snaps {
+		s := &snaps[i]
+		seen[s.ID] = struct{}{}
+		if s.PIDs == 0 {
+			// No stats this round; keep the history but don't extend it.
+			continue
+		}
+		h := append(t.hist[s.ID], s.PIDs)
+		if len(h) > t.Samples {
+			h = h[len(h)-t.Samples:]
+		}
+		t.hist[s.ID] = h
+		s.PIDsGrowing = len(h) == t.Samples && nonDecreasing(h) && h[len(h)-1] > h[0]
+	}
+	for id := range t.hist {
+		if _, ok := seen[id]; !ok {
+			delete(t.hist, id)
+		}
+	}
+}
+
+func nonDecreasing(v []int) bool {
+	for i := 1; i < len(v); i++ {
+		if v[i] < v[i-1] {
+			return false
+		}
+	}
+	return true
+}
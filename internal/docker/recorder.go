@@ -0,0 +1,160 @@
+package docker
+
+import (
+	"sort"
+	"time"
+)
+
+// Recorder accumulates what a watch session saw, for the summary printed
+// when it ends: per-container CPU and memory ranges, the I/O observed while
+// watching and the state changes between refreshes.
+type Recorder struct {
+	// Series keeps every refresh's readings for SessionSummary.Ticks.
+	Series bool
+
+	start      time.Time
+	refreshes  int
+	containers map[string]*ContainerSummary // by ID
+	io         map[string]*ioCounters
+	states     map[string]string // last seen State by ID
+	changes    []StateChange
+	ticks      []Tick
+}
+
+// SessionSummary describes a finished watch session.
+type SessionSummary struct {
+	Start      time.Time          `json:"start"`
+	End        time.Time          `json:"end"`
+	Refreshes  int                `json:"refreshes"`
+	Containers []ContainerSummary `json:"containers"`
+	Changes    []StateChange      `json:"changes,omitempty"`
+	// Ticks is the full time series, when the recorder kept it.
+	Ticks []Tick `json:"ticks,omitempty"`
+}
+
+// Duration is how long the session ran.
+func (s SessionSummary) Duration() time.Duration { return s.End.Sub(s.Start) }
+
+// ContainerSummary aggregates one container's readings over a session.
+// CPU and memory figures only cover refreshes with fresh stats.
+type ContainerSummary struct {
+	Name    string  `json:"name"`
+	ID      string  `json:"id"`
+	Samples int     `json:"samples"`
+	CPUMin  float64 `json:"cpu_min"`
+	CPUAvg  float64 `json:"cpu_avg"`
+	CPUMax  float64 `json:"cpu_max"`
+	MemMin  uint64  `json:"mem_min"`
+	MemAvg  uint64  `json:"mem_avg"`
+	MemMax  uint64  `json:"mem_max"`
+	// I/O observed during the session, carried across counter resets.
+	NetRx      uint64 `json:"net_rx"`
+	NetTx      uint64 `json:"net_tx"`
+	BlockRead  uint64 `json:"block_read"`
+	BlockWrite uint64 `json:"block_write"`
+
+	cpuSum float64
+	memSum float64
+}
+
+// StateChange is a container entering a new state between two refreshes.
+// From is empty for a container that appeared while watching.
+type StateChange struct {
+	Time time.Time `json:"time"`
+	Name string    `json:"name"`
+	From string    `json:"from,omitempty"`
+	To   string    `json:"to"`
+}
+
+// Tick is one refresh's readings. I/O counters are the raw cumulative
+// values Docker reports.
+type Tick struct {
+	Time       time.Time `json:"time"`
+	Containers []Reading `json:"containers"`
+}
+
+// Reading is one container's stats at a Tick.
+type Reading struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	CPUPercent float64 `json:"cpu_percent"`
+	MemUsage   uint64  `json:"mem_usage"`
+	MemLimit   uint64  `json:"mem_limit"`
+	NetRx      uint64  `json:"net_rx"`
+	NetTx      uint64  `json:"net_tx"`
+	BlockRead  uint64  `json:"block_read"`
+	BlockWrite uint64  `json:"block_write"`
+	PIDs       int     `json:"pids"`
+}
+
+// NewRecorder returns a recorder whose session starts now.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		start:      time.Now(),
+		containers: map[string]*ContainerSummary{},
+		io:         map[string]*ioCounters{},
+		states:     map[string]string{},
+	}
+}
+
+// Apply records one refresh. It expects raw I/O counters, so it must run
+// before SessionIO rewrites them.
+func (r *Recorder) Apply(snaps []ContainerSnapshot) {
+	now := time.Now()
+	tick := Tick{Time: now}
+	for _, s := range snaps {
+		if prev, ok := r.states[s.ID]; ok && prev != s.State {
+			r.changes = append(r.changes, StateChange{Time: now, Name: s.Name, From: prev, To: s.State})
+		} else if !ok && r.refreshes > 0 && !s.Gone {
+			r.changes = append(r.changes, StateChange{Time: now, Name: s.Name, To: s.State})
+		}
+		r.states[s.ID] = s.State
+
+		if s.StatsUnavailable || s.Stale || s.Gone || s.State != "running" {
+			continue
+		}
+		c := r.containers[s.ID]
+		if c == nil {
+			c = &ContainerSummary{Name: s.Name, ID: s.ID, CPUMin: s.CPUPercent, MemMin: s.MemUsage}
+			r.containers[s.ID] = c
+		}
+		c.Samples++
+		c.CPUMin, c.CPUMax = min(c.CPUMin, s.CPUPercent), max(c.CPUMax, s.CPUPercent)
+		c.MemMin, c.MemMax = min(c.MemMin, s.MemUsage), max(c.MemMax, s.MemUsage)
+		c.cpuSum += s.CPUPercent
+		c.memSum += float64(s.MemUsage)
+
+		raw := [4]uint64{s.NetRx, s.NetTx, s.BlockRead, s.BlockWrite}
+		cnt, ok := r.io[s.ID]
+		if !ok {
+			cnt = &ioCounters{base: raw, last: raw}
+			r.io[s.ID] = cnt
+		}
+		out := cnt.advance(raw)
+		c.NetRx, c.NetTx, c.BlockRead, c.BlockWrite = out[0], out[1], out[2], out[3]
+
+		if r.Series {
+			tick.Containers = append(tick.Containers, Reading{
+				ID: s.ID, Name: s.Name, CPUPercent: s.CPUPercent, MemUsage: s.MemUsage, MemLimit: s.MemLimit,
+				NetRx: s.NetRx, NetTx: s.NetTx, BlockRead: s.BlockRead, BlockWrite: s.BlockWrite, PIDs: s.PIDs,
+			})
+		}
+	}
+	if r.Series {
+		r.ticks = append(r.ticks, tick)
+	}
+	r.refreshes++
+}
+
+// Summary returns the session up to now, containers ordered by name.
+func (r *Recorder) Summary() SessionSummary {
+	sum := SessionSummary{Start: r.start, End: time.Now(), Refreshes: r.refreshes, Changes: r.changes, Ticks: r.ticks}
+	for _, c := range r.containers {
+		cs := *c
+		cs.CPUAvg = c.cpuSum / float64(c.Samples)
+		cs.MemAvg = uint64(c.memSum / float64(c.Samples))
+		sum.Containers = append(sum.Containers, cs)
+	}
+	sort.Slice(sum.Containers, func(i, j int) bool { return sum.Containers[i].Name < sum.Containers[j].Name })
+	return sum
+}
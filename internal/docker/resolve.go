@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/filters"
+)
+
+// Resolve picks the single container that query refers to. Matching is
+// tried from strictest to loosest, and the first tier with any hit wins:
+// exact name or ID, name or ID prefix, name substring, then a fuzzy
+// subsequence of the name (e.g. "wbpr" for "web-prod"). Comparisons are
+// case-insensitive. More than one hit in the winning tier is an
+// *AmbiguousError listing the candidates.
+func Resolve(snaps []ContainerSnapshot, query string) (ContainerSnapshot, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return ContainerSnapshot{}, fmt.Errorf("empty container name")
+	}
+	tiers := []func(name, id string) bool{
+		func(name, id string) bool { return name == q || id == q },
+		func(name, id string) bool { return strings.HasPrefix(name, q) || strings.HasPrefix(id, q) },
+		func(name, _ string) bool { return strings.Contains(name, q) },
+		func(name, _ string) bool { return isSubsequence(q, name) },
+	}
+	for _, match := range tiers {
+		var hits []ContainerSnapshot
+		for _, s := range snaps {
+			if match(strings.ToLower(s.Name), strings.ToLower(s.ID)) {
+				hits = append(hits, s)
+			}
+		}
+		switch len(hits) {
+		case 0:
+			continue
+		case 1:
+			return hits[0], nil
+		default:
+			sort.Slice(hits, func(i, j int) bool { return strings.ToLower(hits[i].Name) < strings.ToLower(hits[j].Name) })
+			return ContainerSnapshot{}, &AmbiguousError{Query: query, Matches: hits}
+		}
+	}
+	return ContainerSnapshot{}, fmt.Errorf("no container matches %q", query)
+}
+
+// AmbiguousError is returned by Resolve when a query matches several
+// containers equally well. Matches are sorted by name.
+type AmbiguousError struct {
+	Query   string
+	Matches []ContainerSnapshot
+}
+
+func (e *AmbiguousError) Error() string {
+	names := make([]string, len(e.Matches))
+	for i, m := range e.Matches {
+		names[i] = m.Name
+	}
+	return fmt.Sprintf("%q matches %d containers: %s", e.Query, len(e.Matches), strings.Join(names, ", "))
+}
+
+// NameFilters returns a filter that lists exactly the containers with these
+// names. Unlike IDs, names survive compose re-creating a container.
+func NameFilters(names []string) filters.Args {
+	f := filters.NewArgs()
+	for _, n := range names {
+		// The daemon matches name filters as regular expressions against
+		// names that may carry a leading slash.
+		f.Add("name", "^/?"+regexp.QuoteMeta(n)+"$")
+	}
+	return f
+}
+
+// isSubsequence reports whether all runes of q appear in s in order.
+func isSubsequence(q, s string) bool {
+	rs := []rune(s)
+	j := 0
+	for _, r := range q {
+		for j < len(rs) && rs[j] != r {
+			j++
+		}
+		if j == len(rs) {
+			return false
+		}
+		j++
+	}
+	return true
+}
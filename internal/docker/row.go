@@ -0,0 +1,217 @@
+package docker
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Row is the serialized form of a ContainerSnapshot, shared by
+// --format=json, the snapshot store, exporters, column plugins and the
+// --where field names. Durations are written in seconds.
+type Row struct {
+	Name         string              `json:"name"`
+	ID           string              `json:"id"`
+	Status       string              `json:"status"`
+	State        string              `json:"state,omitempty"`
+	Command      string              `json:"command,omitempty"`
+	Labels       map[string]string   `json:"labels,omitempty"`
+	Networks     []NetworkAttachment `json:"networks,omitempty"`
+	HostNetwork  bool                `json:"host_network,omitempty"`
+	Image        string              `json:"image,omitempty"`
+	ImageID      string              `json:"image_id,omitempty"`
+	ImageDigest  string              `json:"image_digest,omitempty"`
+	ImageCreated *time.Time          `json:"image_created,omitempty"`
+	// ImageTooOld is set by the JSON renderer when the image is older than
+	// --image-max-age.
+	ImageTooOld bool     `json:"image_too_old,omitempty"`
+	Ports       []string `json:"ports,omitempty"`
+	Mounts      []Mount  `json:"mounts,omitempty"`
+
+	CPUPercent     float64 `json:"cpu_percent"`
+	MemUsage       uint64  `json:"mem_usage"`
+	MemLimit       uint64  `json:"mem_limit"`
+	MemPercent     float64 `json:"mem_percent"`
+	NetRx          uint64  `json:"net_rx"`
+	NetTx          uint64  `json:"net_tx"`
+	BlockRead      uint64  `json:"block_read"`
+	BlockWrite     uint64  `json:"block_write"`
+	NetRxRate      float64 `json:"net_rx_rate,omitempty"`
+	NetTxRate      float64 `json:"net_tx_rate,omitempty"`
+	BlockReadRate  float64 `json:"block_read_rate,omitempty"`
+	BlockWriteRate float64 `json:"block_write_rate,omitempty"`
+	PIDs           int     `json:"pids"`
+	PIDsLimit      uint64  `json:"pids_limit,omitempty"`
+	FDs            int     `json:"fds,omitempty"`
+	FDLimit        uint64  `json:"fd_limit,omitempty"`
+	Conns          *int    `json:"connections,omitempty"`
+	CPUSeconds     float64 `json:"cpu_seconds,omitempty"`
+	OnlineCPUs     int     `json:"online_cpus,omitempty"`
+	CPULimit       float64 `json:"cpu_limit,omitempty"`
+
+	CollectedAt time.Time `json:"collected_at"`
+	// Host is set by the JSON renderer from the daemon's host info.
+	Host *HostInfo `json:"host,omitempty"`
+
+	RecentRestarts   int     `json:"recent_restarts,omitempty"`
+	Flapping         bool    `json:"flapping,omitempty"`
+	Zombies          int     `json:"zombies,omitempty"`
+	PIDsGrowing      bool    `json:"pids_growing,omitempty"`
+	CPUAnomaly       bool    `json:"cpu_anomaly,omitempty"`
+	MemAnomaly       bool    `json:"mem_anomaly,omitempty"`
+	MemFullInSeconds float64 `json:"mem_full_in_seconds,omitempty"`
+	LogErrors        *int    `json:"log_errors,omitempty"`
+	// LogErrorsTruncated marks LogErrors as a lower bound.
+	LogErrorsTruncated bool              `json:"log_errors_truncated,omitempty"`
+	LastLog            string            `json:"last_log,omitempty"`
+	Extra              map[string]string `json:"extra,omitempty"`
+	ExitCode           *int              `json:"exit_code,omitempty"`
+	FinishedAt         *time.Time        `json:"finished_at,omitempty"`
+	New                bool              `json:"new,omitempty"`
+	Gone               bool              `json:"gone,omitempty"`
+
+	StatsUnavailable bool   `json:"stats_unavailable,omitempty"`
+	StatsError       string `json:"stats_error,omitempty"`
+	Stale            bool   `json:"stale,omitempty"`
+	// Partial is set by the JSON renderer on every row when any
+	// container's stats failed, so consumers know the collection as a
+	// whole is incomplete.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// NewRow converts s to its serialized form.
+func NewRow(s ContainerSnapshot) Row {
+	return Row{
+		Name:               s.Name,
+		ID:                 s.ID,
+		Status:             s.Status,
+		State:              s.State,
+		Command:            s.Command,
+		Labels:             s.Labels,
+		Networks:           s.Networks,
+		HostNetwork:        s.HostNetwork,
+		Image:              s.Image,
+		ImageID:            s.ImageID,
+		ImageDigest:        s.ImageDigest,
+		ImageCreated:       s.ImageCreated,
+		Ports:              s.Ports,
+		Mounts:             s.Mounts,
+		CPUPercent:         s.CPUPercent,
+		MemUsage:           s.MemUsage,
+		MemLimit:           s.MemLimit,
+		MemPercent:         s.MemPercent,
+		NetRx:              s.NetRx,
+		NetTx:              s.NetTx,
+		BlockRead:          s.BlockRead,
+		BlockWrite:         s.BlockWrite,
+		NetRxRate:          s.NetRxRate,
+		NetTxRate:          s.NetTxRate,
+		BlockReadRate:      s.BlockReadRate,
+		BlockWriteRate:     s.BlockWriteRate,
+		PIDs:               s.PIDs,
+		PIDsLimit:          s.PIDsLimit,
+		FDs:                s.FDs,
+		FDLimit:            s.FDLimit,
+		Conns:              s.Conns,
+		CPUSeconds:         s.CPUTime.Seconds(),
+		OnlineCPUs:         s.OnlineCPUs,
+		CPULimit:           s.CPULimit,
+		CollectedAt:        s.CollectedAt,
+		RecentRestarts:     s.RecentRestarts,
+		Flapping:           s.Flapping,
+		Zombies:            s.Zombies,
+		PIDsGrowing:        s.PIDsGrowing,
+		CPUAnomaly:         s.CPUAnomaly,
+		MemAnomaly:         s.MemAnomaly,
+		MemFullInSeconds:   s.MemFullIn.Seconds(),
+		LogErrors:          s.LogErrors,
+		LogErrorsTruncated: s.LogErrorsTruncated,
+		LastLog:            s.LastLog,
+		Extra:              s.Extra,
+		ExitCode:           s.ExitCode,
+		FinishedAt:         s.FinishedAt,
+		New:                s.New,
+		Gone:               s.Gone,
+		StatsUnavailable:   s.StatsUnavailable,
+		StatsError:         s.StatsError,
+		Stale:              s.Stale,
+	}
+}
+
+// Snapshot converts r back, e.g. for snapshots read from the store. Fields
+// only the renderer sets are dropped.
+func (r Row) Snapshot() ContainerSnapshot {
+	return ContainerSnapshot{
+		Name:               r.Name,
+		ID:                 r.ID,
+		Status:             r.Status,
+		State:              r.State,
+		Command:            r.Command,
+		Labels:             r.Labels,
+		Networks:           r.Networks,
+		HostNetwork:        r.HostNetwork,
+		Image:              r.Image,
+		ImageID:            r.ImageID,
+		ImageDigest:        r.ImageDigest,
+		ImageCreated:       r.ImageCreated,
+		Ports:              r.Ports,
+		Mounts:             r.Mounts,
+		CPUPercent:         r.CPUPercent,
+		MemUsage:           r.MemUsage,
+		MemLimit:           r.MemLimit,
+		MemPercent:         r.MemPercent,
+		NetRx:              r.NetRx,
+		NetTx:              r.NetTx,
+		BlockRead:          r.BlockRead,
+		BlockWrite:         r.BlockWrite,
+		NetRxRate:          r.NetRxRate,
+		NetTxRate:          r.NetTxRate,
+		BlockReadRate:      r.BlockReadRate,
+		BlockWriteRate:     r.BlockWriteRate,
+		PIDs:               r.PIDs,
+		PIDsLimit:          r.PIDsLimit,
+		FDs:                r.FDs,
+		FDLimit:            r.FDLimit,
+		Conns:              r.Conns,
+		CPUTime:            seconds(r.CPUSeconds),
+		OnlineCPUs:         r.OnlineCPUs,
+		CPULimit:           r.CPULimit,
+		CollectedAt:        r.CollectedAt,
+		RecentRestarts:     r.RecentRestarts,
+		Flapping:           r.Flapping,
+		Zombies:            r.Zombies,
+		PIDsGrowing:        r.PIDsGrowing,
+		CPUAnomaly:         r.CPUAnomaly,
+		MemAnomaly:         r.MemAnomaly,
+		MemFullIn:          seconds(r.MemFullInSeconds),
+		LogErrors:          r.LogErrors,
+		LogErrorsTruncated: r.LogErrorsTruncated,
+		LastLog:            r.LastLog,
+		Extra:              r.Extra,
+		ExitCode:           r.ExitCode,
+		FinishedAt:         r.FinishedAt,
+		New:                r.New,
+		Gone:               r.Gone,
+		StatsUnavailable:   r.StatsUnavailable,
+		StatsError:         r.StatsError,
+		Stale:              r.Stale,
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// MarshalJSON writes s as its Row, so every consumer sees one schema.
+func (s ContainerSnapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewRow(s))
+}
+
+// UnmarshalJSON reads a Row.
+func (s *ContainerSnapshot) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+	*s = r.Snapshot()
+	return nil
+}
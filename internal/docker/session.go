@@ -0,0 +1,124 @@
+package docker
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionIO rewrites cumulative net and block I/O counters as totals since a
+// baseline (by default, when watching started), so users can measure the
+// traffic an action generated. Counters that go backwards, as after a
+// container restart, are carried over rather than producing negative values.
+type SessionIO struct {
+	mu       sync.Mutex
+	since    time.Time
+	counters map[string]*ioCounters
+}
+
+type ioCounters struct {
+	base  [4]uint64 // raw values at the baseline (or last counter reset)
+	carry [4]uint64 // traffic accumulated before a counter reset
+	last  [4]uint64 // most recent raw values
+}
+
+// advance records the latest raw counters and returns the growth since the
+// baseline. A counter that went backwards was reset; what it measured
+// before is banked in carry.
+func (c *ioCounters) advance(raw [4]uint64) [4]uint64 {
+	var out [4]uint64
+	for k := range raw {
+		if raw[k] < c.last[k] {
+			c.carry[k] += c.last[k] - c.base[k]
+			c.base[k] = 0
+		}
+		c.last[k] = raw[k]
+		out[k] = c.carry[k] + raw[k] - c.base[k]
+	}
+	return out
+}
+
+// NewSessionIO returns a tracker whose baseline is now.
+func NewSessionIO() *SessionIO {
+	return &SessionIO{since: time.Now(), counters: map[string]*ioCounters{}}
+}
+
+// Since reports when the current baseline was taken.
+func (t *SessionIO) Since() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.since
+}
+
+// Reset moves the baseline to now; the next Apply starts every container
+// from zero again.
+func (t *SessionIO) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.since = time.Now()
+	t.counters = map[string]*ioCounters{}
+}
+
+// Apply replaces the I/O counters in snaps with their growth since the
+// baseline. Containers first seen after the baseline start from zero.
+// Rows without fresh stats are left untouched.
+func (t *SessionIO) Apply(snaps []ContainerSnapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range snaps {
+		s := &snaps[i]
+		if s.StatsUnavailable || s.Stale || s.Gone || s.State != "running" {
+			continue
+		}
+		raw := [4]uint64{s.NetRx, s.NetTx, s.BlockRead, s.BlockWrite}
+		c, ok := t.counters[s.ID]
+		if !ok {
+			c = &ioCounters{base: raw, last: raw}
+			t.counters[s.ID] = c
+		}
+		out := c.advance(raw)
+		s.NetRx, s.NetTx, s.BlockRead, s.BlockWrite = out[0], out[1], out[2], out[3]
+	}
+}
+
+// IORates derives per-second net and block I/O rates from consecutive
+// collections, so watch mode can rank containers by current traffic rather
+// than lifetime totals.
+type IORates struct {
+	prev map[string]ContainerSnapshot
+}
+
+// NewIORates returns an empty rate tracker.
+func NewIORates() *IORates {
+	return &IORates{prev: map[string]ContainerSnapshot{}}
+}
+
+// Apply sets the *Rate fields on snaps from the change since the previous
+// call. The first sample of a container, and samples where a counter went
+// backwards (restart), yield no rate.
+func (r *IORates) Apply(snaps []ContainerSnapshot) {
+	next := make(map[string]ContainerSnapshot, len(snaps))
+	for i := range snaps {
+		s := &snaps[i]
+		if s.StatsUnavailable || s.Stale || s.State != "running" {
+			continue
+		}
+		next[s.ID] = *s
+		p, ok := r.prev[s.ID]
+		if !ok {
+			continue
+		}
+		secs := s.CollectedAt.Sub(p.CollectedAt).Seconds()
+		if secs <= 0 {
+			continue
+		}
+		rate := func(cur, old uint64) float64 {
+			if cur < old {
+				return 0
+			}
+			return float64(cur-old) / secs
+		}
+		s.NetRxRate, s.NetTxRate = rate(s.NetRx, p.NetRx), rate(s.NetTx, p.NetTx)
+		s.BlockReadRate, s.BlockWriteRate = rate(s.BlockRead, p.BlockRead), rate(s.BlockWrite, p.BlockWrite)
+	}
+	r.prev = next
+}
@@ -0,0 +1,36 @@
+package docker
+
+// LastKnown remembers the most recent successful sample per container ID so
+// repeated collections (watch mode) can fall back to it when a stats read
+// times out instead of blanking the row.
+type LastKnown map[string]ContainerSnapshot
+
+// Apply updates the cache from snaps and, for rows whose stats were
+// unavailable, restores the last known metrics and marks them Stale. Entries
+// for containers no longer listed are dropped.
+func (lk LastKnown) Apply(snaps []ContainerSnapshot) {
+	seen := make(map[string]struct{}, len(snaps))
+	for i := range snaps {
+		s := &snaps[i]
+		seen[s.ID] = struct{}{}
+		if !s.StatsUnavailable {
+			if s.Status != "ERROR" {
+				lk[s.ID] = *s
+			}
+			continue
+		}
+		prev, ok := lk[s.ID]
+		if !ok {
+			continue
+		}
+		status, reason := s.Status, s.StatsError
+		*s = prev
+		s.Status, s.StatsError = status, reason
+		s.Stale = true
+	}
+	for id := range lk {
+		if _, ok := seen[id]; !ok {
+			delete(lk, id)
+		}
+	}
+}
@@ -3,20 +3,48 @@ package docker
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"math"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 )
 
-// ContainerSnapshot is a one-shot snapshot of container runtime metrics.
+// ContainerSnapshot is a one-shot snapshot of container runtime metrics. It
+// is serialized as a Row.
 type ContainerSnapshot struct {
-	ID         string
-	Name       string
-	Status     string
+	ID       string
+	Name     string
+	Status   string
+	State    string // raw Docker state: running, exited, paused...
+	Command  string
+	Labels   map[string]string
+	Networks []NetworkAttachment
+	// HostNetwork is set for containers sharing the host's network
+	// namespace (--network host); their stats have no network counters.
+	HostNetwork bool
+	// Image is the reference the container was created from (e.g.
+	// "nginx:1.27"), ImageID the local image ID, and ImageDigest the
+	// registry digest when resolved (see ImageDigests).
+	Image       string
+	ImageID     string
+	ImageDigest string
+	// ImageCreated is when the image was built, resolved with ImageDigest.
+	ImageCreated *time.Time
+	// Ports lists published and exposed ports as `docker ps` shows them,
+	// e.g. "0.0.0.0:8080->80/tcp".
+	Ports []string
+	// Mounts lists volumes, bind mounts and tmpfs mounts.
+	Mounts     []Mount
 	CPUPercent float64
 	MemUsage   uint64 // bytes
 	MemLimit   uint64 // bytes
@@ -26,57 +54,406 @@ type ContainerSnapshot struct {
 	BlockRead  uint64 // bytes
 	BlockWrite uint64 // bytes
 	PIDs       int
+	// PIDsLimit is the container's pids cgroup limit (--pids-limit); zero
+	// when unlimited. Forks fail with EAGAIN once PIDs reaches it.
+	PIDsLimit uint64
+	// FDs and FDLimit are the open file descriptors and soft open-files
+	// limit of the container process closest to that limit; set only with
+	// --fds (see CountFDs).
+	FDs     int
+	FDLimit uint64
+	// Conns is the number of established TCP connections, when counted
+	// with --conns (see CountConnections); nil when not counted.
+	Conns *int
+	// CPUTime is the total CPU time the container has used since it
+	// started, across all cores.
+	CPUTime time.Duration
+	// Rates in bytes per second since the previous watch refresh; zero
+	// outside watch mode. See IORates.
+	NetRxRate      float64
+	NetTxRate      float64
+	BlockReadRate  float64
+	BlockWriteRate float64
+	// CollectedAt is when the metrics were read (or the container listed,
+	// for containers without stats).
+	CollectedAt time.Time
+	// OnlineCPUs is how many CPUs the container can run on, from its stats.
+	// CPULimit is its CPU quota in cores (zero when unlimited), read only
+	// with --cpu-scale=limit (see CPULimits).
+	OnlineCPUs int
+	CPULimit   float64
+	// StatsUnavailable is set when the container is still listed but its
+	// stats could not be read this time (timeout, daemon pressure). Status
+	// keeps its listed value and the metric fields are left zero.
+	StatsUnavailable bool
+	// StatsError is why the stats read failed, for --debug.
+	StatsError string
+	// ExitCode and FinishedAt are set for exited containers (with --all).
+	ExitCode   *int
+	FinishedAt *time.Time
+	// RecentRestarts counts restarts seen within the flapping window and
+	// Flapping is set when that exceeds the threshold (watch mode only).
+	RecentRestarts int
+	Flapping       bool
+	// Zombies counts defunct processes (--zombies); PIDsGrowing is set in
+	// watch mode when the PID count keeps climbing across samples.
+	Zombies     int
+	PIDsGrowing bool
+	// CPUAnomaly and MemAnomaly mark readings far outside the container's
+	// own baseline (see Baseline).
+	CPUAnomaly bool
+	MemAnomaly bool
+	// MemFullIn estimates when memory usage reaches the limit at its
+	// current growth rate (watch mode only, see MemTrend); zero when usage
+	// is not trending upward.
+	MemFullIn time.Duration
+	// LogErrors counts recent log lines matching the error pattern
+	// (--log-errors); nil when not collected. LogErrorsTruncated is set when
+	// the window held more log than is read, so the count is a lower bound.
+	LogErrors          *int
+	LogErrorsTruncated bool
+	// LastLog is the container's most recent log line (--show-last-log).
+	LastLog string
+	// Extra holds additional column values keyed by column header, as
+	// supplied by column plugins.
+	Extra map[string]string
+	// New marks a container that appeared within the last few watch
+	// refreshes. See Arrivals.
+	New bool
+	// Gone marks a row kept briefly after its container left the list;
+	// Status then says why. See Departures.
+	Gone bool
+	// Stale marks metrics carried over from an earlier sample because the
+	// current stats read failed. See LastKnown.
+	Stale bool
 }
 
-// CollectSnapshots lists containers and collects a single stats sample for each.
-// For stopped containers, metrics are zeroed and status reflects their state.
-func CollectSnapshots(ctx context.Context, cli *client.Client, includeAll bool) ([]ContainerSnapshot, error) {
-	// List containers. We use All=true only if includeAll is set; otherwise only running.
-	listOpts := container.ListOptions{All: includeAll}
-	containers, err := cli.ContainerList(ctx, listOpts)
+// statsTimeout bounds one stats read once it has a daemon connection. A
+// healthy non-streaming read takes a second or two, as the daemon waits for
+// its next sample. inspectTimeout bounds the inspect of an exited container.
+const (
+	statsTimeout   = 3 * time.Second
+	inspectTimeout = 1500 * time.Millisecond
+)
+
+// DefaultConcurrency is the number of parallel stats requests used when
+// CollectOptions.Concurrency is not set.
+const DefaultConcurrency = 16
+
+// CollectOptions tunes how snapshots are gathered.
+type CollectOptions struct {
+	// IncludeAll lists stopped containers too (with zeroed metrics).
+	IncludeAll bool
+	// NoStats returns the container list as is, without any per-container
+	// stats or inspect calls.
+	NoStats bool
+	// Concurrency bounds parallel stats requests to the daemon.
+	// Values <= 0 fall back to DefaultConcurrency. Ignored when Limiter is set.
+	Concurrency int
+	// Limiter, when non-nil, replaces the fixed Concurrency bound with an
+	// adaptive one driven by observed stats latency and errors.
+	Limiter *AdaptiveLimiter
+	// Timings, when non-nil, is filled with how long each collection phase took.
+	Timings *CollectTimings
+	// Sample, when > 0, computes CPU% from two one-shot stats reads taken this
+	// far apart instead of relying on the daemon's single-read pre-sample.
+	Sample time.Duration
+	// Cgroup, when non-nil, reads running containers' metrics from the
+	// cgroup filesystem instead of the stats API; containers it cannot find
+	// fall back to the API. Sample is ignored for those it handles.
+	Cgroup *CgroupCollector
+	// Filters, when set, restricts collection to the containers matching
+	// them (see ParseFilters); others are not listed or sampled at all.
+	Filters filters.Args
+	// Lister, when non-nil, is the container list Collector and the other
+	// views of a refresh share; watch loops call its Next before each
+	// collection.
+	Lister *Lister
+	// Collector lists and samples the containers: a DockerCollector, another
+	// runtime or a fake in tests. Exit details of stopped containers and
+	// Sample are skipped for collectors that don't implement ExitReader and
+	// Sampler.
+	Collector Collector
+	// Exits, when non-nil, caches exited containers' inspect results
+	// across collections.
+	Exits *ExitCache
+	// Progress, when non-nil, is called after each stats request finishes
+	// with the number done so far and the total. It may be called concurrently.
+	Progress func(done, total int)
+}
+
+// CollectTimings breaks down where a CollectSnapshots call spent its time.
+type CollectTimings struct {
+	List       time.Duration
+	Stats      time.Duration
+	Containers []ContainerTiming
+}
+
+// ContainerTiming is the wall time of a single container's stats request.
+type ContainerTiming struct {
+	ID       string
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// ListContainers returns snapshots with listing details only (no stats),
+// from l's list for this refresh. Only running containers are included
+// unless includeAll is set.
+func ListContainers(ctx context.Context, l *Lister, includeAll bool) ([]ContainerSnapshot, error) {
+	return ListFiltered(ctx, l, includeAll, filters.Args{})
+}
+
+// ListFiltered is ListContainers restricted to containers matching f (see
+// ParseFilters).
+func ListFiltered(ctx context.Context, l *Lister, includeAll bool, f filters.Args) ([]ContainerSnapshot, error) {
+	return listSnapshots(ctx, l.cli, l, includeAll, f)
+}
+
+// listSnapshots lists containers, through l when it is set, as snapshots
+// without stats.
+func listSnapshots(ctx context.Context, cli *client.Client, l *Lister, includeAll bool, f filters.Args) ([]ContainerSnapshot, error) {
+	containers, err := listContainers(ctx, cli, l, includeAll, f)
 	if err != nil {
 		return nil, err
 	}
-
+	now := time.Now().UTC()
 	snapshots := make([]ContainerSnapshot, len(containers))
-	runningIdx := make([]int, 0, len(containers))
 	for i, c := range containers {
 		snapshots[i] = ContainerSnapshot{
-			ID:     c.ID,
-			Name:   deriveName(c.Names),
-			Status: deriveStatus(c.State, c.Status),
+			ID:          c.ID,
+			Name:        deriveName(c.Names),
+			Status:      deriveStatus(c.State, c.Status),
+			State:       c.State,
+			Command:     c.Command,
+			Labels:      c.Labels,
+			Networks:    networkAttachments(c.NetworkSettings),
+			Image:       c.Image,
+			ImageID:     c.ImageID,
+			Ports:       formatPorts(c.Ports),
+			HostNetwork: c.HostConfig.NetworkMode == "host",
+			Mounts:      mountsOf(c.Mounts),
+
+			CollectedAt: now,
 		}
-		if c.State == "running" {
+	}
+	return snapshots, nil
+}
+
+// CollectSnapshots lists containers through opts.Collector and collects a
+// single stats sample for each. For stopped containers, metrics are zeroed
+// and status reflects their state.
+func CollectSnapshots(ctx context.Context, opts CollectOptions) ([]ContainerSnapshot, error) {
+	c := opts.Collector
+	if c == nil {
+		return nil, errors.New("collect: no collector")
+	}
+	exitReader, _ := c.(ExitReader)
+	sampler, _ := c.(Sampler)
+	// List containers. We use All=true only if IncludeAll is set; otherwise only running.
+	listStart := time.Now()
+	snapshots, err := c.ListContainers(ctx, opts.IncludeAll, opts.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Timings != nil {
+		*opts.Timings = CollectTimings{List: time.Since(listStart)}
+	}
+	if opts.NoStats {
+		return snapshots, nil
+	}
+
+	runningIdx := make([]int, 0, len(snapshots))
+	exitedIdx := make([]int, 0)
+	for i, s := range snapshots {
+		switch s.State {
+		case "running":
 			runningIdx = append(runningIdx, i)
+		case "exited":
+			if e, ok := opts.Exits.get(s.ID, s.Status); ok {
+				applyExit(&snapshots[i], e)
+			} else if exitReader != nil {
+				exitedIdx = append(exitedIdx, i)
+			}
+		case "paused":
+			// Frozen: there is no activity to measure, so no stats are read
+			// and the metrics stay empty; renderers show the row as paused
+			// rather than as a failed read.
 		}
 	}
 
-	// Parallelize stats fetch for running containers with a bounded semaphore and per-call timeout.
-	if len(runningIdx) == 0 {
+	// Parallelize daemon calls with a bounded semaphore and per-call timeout.
+	if len(runningIdx) == 0 && len(exitedIdx) == 0 {
 		return snapshots, nil
 	}
-	concurrency := 16
-	if len(runningIdx) < concurrency {
-		concurrency = len(runningIdx)
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
 	}
 	sem := make(chan struct{}, concurrency)
+	acquire := func() { sem <- struct{}{} }
+	release := func(time.Duration, error) { <-sem }
+	if opts.Limiter != nil {
+		acquire, release = opts.Limiter.Acquire, opts.Limiter.Release
+	}
+
+	// Exit code and finish time are not in the list. A failed read just
+	// leaves the listed status in place.
+	runBounded(exitedIdx, acquire, release, func(_, i int) error {
+		cctx, cancel := callTimeout(ctx, inspectTimeout)
+		defer cancel()
+		code, finished, err := exitReader.Exit(cctx, snapshots[i].ID)
+		if err != nil {
+			slog.Debug("reading exit details failed", "container", snapshots[i].Name, "err", err)
+			return err
+		}
+		e := exitInfo{code: code, finished: finished}
+		opts.Exits.put(snapshots[i].ID, e)
+		applyExit(&snapshots[i], e)
+		return nil
+	}, nil)
+	if len(runningIdx) == 0 {
+		return snapshots, nil
+	}
+	// With --sample, take a first CPU reading for every container, then wait
+	// out the rest of the window so the second pass measures a concrete interval.
+	var first []*container.Stats
+	if opts.Sample > 0 && opts.Cgroup == nil && sampler != nil {
+		first = make([]*container.Stats, len(runningIdx))
+		sampleStart := time.Now()
+		runBounded(runningIdx, acquire, release, func(n, i int) error {
+			cctx, cancel := callTimeout(ctx, statsTimeout)
+			defer cancel()
+			sj, err := sampler.FirstSample(cctx, snapshots[i].ID)
+			if err == nil {
+				first[n] = sj
+			}
+			return err
+		}, nil)
+		select {
+		case <-time.After(time.Until(sampleStart.Add(opts.Sample))):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var timings []ContainerTiming
+	if opts.Timings != nil {
+		timings = make([]ContainerTiming, len(runningIdx))
+	}
+	statsStart := time.Now()
+	var done atomic.Int64
+	runBounded(runningIdx, acquire, release, func(n, i int) error {
+		if opts.Cgroup != nil {
+			cctx, cancel := context.WithTimeout(ctx, statsTimeout)
+			err := opts.Cgroup.Populate(cctx, &snapshots[i])
+			cancel()
+			if err == nil {
+				return nil
+			}
+		}
+		cctx, cancel := callTimeout(ctx, statsTimeout)
+		defer cancel()
+		if first == nil || first[n] == nil {
+			// No --sample, or its first reading failed: rely on the
+			// runtime's own pre-sample.
+			return c.Stats(cctx, &snapshots[i])
+		}
+		return sampler.StatsSince(cctx, &snapshots[i], first[n])
+	}, func(n, i int, elapsed time.Duration, err error) {
+		if timings != nil {
+			timings[n] = ContainerTiming{ID: snapshots[i].ID, Name: snapshots[i].Name, Duration: elapsed, Err: err}
+		}
+		if err != nil {
+			markStatsFailed(&snapshots[i], err)
+		} else {
+			snapshots[i].CollectedAt = time.Now().UTC()
+		}
+		if opts.Progress != nil {
+			opts.Progress(int(done.Add(1)), len(runningIdx))
+		}
+	})
+	if opts.Timings != nil {
+		opts.Timings.Stats = time.Since(statsStart)
+		opts.Timings.Containers = timings
+	}
+	return snapshots, nil
+}
+
+// runBounded calls fn for every index in parallel, holding a slot from
+// acquire/release for the duration of each call. after, when non-nil, runs
+// once fn returns with its wall time and error. n is the position in indices.
+func runBounded(indices []int, acquire func(), release func(time.Duration, error), fn func(n, i int) error, after func(n, i int, elapsed time.Duration, err error)) {
 	var wg sync.WaitGroup
-	for _, idx := range runningIdx {
-		i := idx
-		sem <- struct{}{}
+	for n, i := range indices {
+		n, i := n, i
+		acquire()
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			defer func() { <-sem }()
-			cctx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
-			defer cancel()
-			if err := populateStats(cctx, cli, &snapshots[i], snapshots[i].ID); err != nil {
-				snapshots[i].Status = "ERROR"
+			start := time.Now()
+			err := fn(n, i)
+			elapsed := time.Since(start)
+			release(elapsed, err)
+			if after != nil {
+				after(n, i, elapsed, err)
 			}
 		}()
 	}
 	wg.Wait()
-	return snapshots, nil
+}
+
+// applyExit sets snap's exit code and rewrites Status compactly, e.g.
+// "Exited (137) 2h13m ago".
+func applyExit(snap *ContainerSnapshot, e exitInfo) {
+	code := e.code
+	snap.ExitCode = &code
+	if e.finished.IsZero() {
+		snap.Status = fmt.Sprintf("Exited (%d)", code)
+		return
+	}
+	finished := e.finished
+	snap.FinishedAt = &finished
+	snap.Status = fmt.Sprintf("Exited (%d) %s ago", code, ShortDuration(time.Since(finished)))
+}
+
+// ShortDuration formats d with its two largest units, e.g. "45s", "12m",
+// "2h13m" or "3d4h".
+func ShortDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours()/24), int(d.Hours())%24)
+	}
+}
+
+// markStatsFailed records a failed stats read. A container that vanished
+// between list and stats is an ERROR row; any other failure is treated as
+// transient and only the metrics are dropped, so one slow call under load
+// yields a partial row rather than an error.
+func markStatsFailed(snap *ContainerSnapshot, err error) {
+	clearMetrics(snap)
+	snap.StatsError = err.Error()
+	if client.IsErrNotFound(err) {
+		snap.Status = "ERROR"
+		return
+	}
+	snap.StatsUnavailable = true
+}
+
+// clearMetrics zeroes the sampled metric fields, keeping listing details.
+func clearMetrics(snap *ContainerSnapshot) {
+	snap.CPUPercent = 0
+	snap.MemUsage, snap.MemLimit, snap.MemPercent = 0, 0, 0
+	snap.NetRx, snap.NetTx = 0, 0
+	snap.BlockRead, snap.BlockWrite = 0, 0
+	snap.PIDs, snap.PIDsLimit = 0, 0
+	snap.CPUTime = 0
 }
 
 func deriveName(names []string) string {
@@ -87,6 +464,31 @@ func deriveName(names []string) string {
 	return strings.TrimPrefix(n, "/")
 }
 
+// formatPorts renders port mappings like `docker ps`, sorted and with
+// duplicates (IPv4/IPv6 bindings of the same mapping) removed.
+func formatPorts(ports []container.Port) []string {
+	seen := make(map[string]bool, len(ports))
+	out := make([]string, 0, len(ports))
+	for _, p := range ports {
+		var s string
+		if p.PublicPort != 0 {
+			ip := p.IP
+			if ip == "" || ip == "::" {
+				ip = "0.0.0.0"
+			}
+			s = fmt.Sprintf("%s:%d->%d/%s", ip, p.PublicPort, p.PrivatePort, p.Type)
+		} else {
+			s = fmt.Sprintf("%d/%s", p.PrivatePort, p.Type)
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
 func deriveStatus(state, status string) string {
 	// Docker container list provides both a brief state (e.g., running) and a
 	// human string in Status (e.g., "Up X minutes"). We prefer Status when set.
@@ -99,13 +501,25 @@ func deriveStatus(state, status string) string {
 	return ""
 }
 
-func populateStats(ctx context.Context, cli *client.Client, snap *ContainerSnapshot, containerID string) error {
-	// Single snapshot: call ContainerStats with streaming=false.
-	stats, err := cli.ContainerStats(ctx, containerID, false)
+// readStats fetches and decodes a single stats document. With oneShot the
+// daemon skips its own ~1s pre-sample, so PreCPUStats is left empty.
+func readStats(ctx context.Context, cli *client.Client, containerID string, oneShot bool) (*container.Stats, error) {
+	var stats container.StatsResponseReader
+	var err error
+	if oneShot {
+		stats, err = cli.ContainerStatsOneShot(ctx, containerID)
+	} else {
+		// Single snapshot: call ContainerStats with streaming=false.
+		stats, err = cli.ContainerStats(ctx, containerID, false)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer stats.Body.Close()
+	defer func() {
+		// Drain before closing so the keep-alive connection goes back to the pool.
+		_, _ = io.Copy(io.Discard, stats.Body)
+		_ = stats.Body.Close()
+	}()
 
 	// Stats JSON structure mirrors types.StatsJSON.
 	decoder := json.NewDecoder(io.LimitReader(stats.Body, 10*1024*1024)) // 10 MiB safety
@@ -113,14 +527,27 @@ func populateStats(ctx context.Context, cli *client.Client, snap *ContainerSnaps
 
 	// Stats endpoint returns a single JSON doc when stream=false.
 	if err := decoder.Decode(&sj); err != nil {
+		return nil, err
+	}
+	return &sj, nil
+}
+
+// populateStats reads stats into snap. A non-nil pre replaces the daemon's
+// PreCPUStats so CPU% covers the window since that earlier reading.
+func populateStats(ctx context.Context, cli *client.Client, snap *ContainerSnapshot, containerID string, pre *container.Stats, oneShot bool) error {
+	sj, err := readStats(ctx, cli, containerID, oneShot)
+	if err != nil {
 		return err
 	}
+	if pre != nil {
+		sj.PreCPUStats, sj.PreRead = pre.CPUStats, pre.Read
+	}
 
 	// CPU percentage: (cpuDelta / systemDelta) * onlineCPUs * 100
-	cpuPercent := computeCPUPercent(&sj)
-	memUsage, memLimit, memPercent := computeMemory(&sj)
-	netRx, netTx := computeNetwork(&sj)
-	blkRead, blkWrite := computeBlockIO(&sj)
+	cpuPercent := computeCPUPercent(sj)
+	memUsage, memLimit, memPercent := computeMemory(sj)
+	netRx, netTx := computeNetwork(sj)
+	blkRead, blkWrite := computeBlockIO(sj)
 	pids := 0
 	if sj.PidsStats.Current != 0 {
 		pids = int(sj.PidsStats.Current)
@@ -135,10 +562,31 @@ func populateStats(ctx context.Context, cli *client.Client, snap *ContainerSnaps
 	snap.BlockRead = blkRead
 	snap.BlockWrite = blkWrite
 	snap.PIDs = pids
+	snap.PIDsLimit = pidsLimit(sj.PidsStats.Limit)
+	snap.CPUTime = computeCPUTime(sj)
+	if !isWindowsStats(sj) {
+		snap.OnlineCPUs = int(onlineCPUs(sj))
+	}
 	return nil
 }
 
+// isWindowsStats reports whether s came from a Windows daemon, which fills
+// NumProcs and the Windows-only memory/storage fields instead of cgroup data.
+func isWindowsStats(s *container.Stats) bool {
+	return s.NumProcs > 0
+}
+
 func computeCPUPercent(s *container.Stats) float64 {
+	if isWindowsStats(s) {
+		// Windows reports CPU time in 100ns intervals and no system usage;
+		// compare against the intervals available across all processors.
+		possible := float64(s.Read.Sub(s.PreRead).Nanoseconds()) / 100 * float64(s.NumProcs)
+		used := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+		if possible <= 0 || used <= 0 || s.PreRead.IsZero() {
+			return 0
+		}
+		return used / possible * 100.0
+	}
 	// Defensive checks: precpu or system cpu usage may be missing/zero.
 	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage - s.PreCPUStats.CPUUsage.TotalUsage)
 	systemDelta := float64(s.CPUStats.SystemUsage - s.PreCPUStats.SystemUsage)
@@ -146,19 +594,45 @@ func computeCPUPercent(s *container.Stats) float64 {
 	if cpuDelta <= 0 || systemDelta <= 0 {
 		return 0
 	}
-	// number of CPUs: prefer OnlineCPUs when present, otherwise len(percpu)
-	var numCPUs float64
-	if s.CPUStats.OnlineCPUs > 0 {
-		numCPUs = float64(s.CPUStats.OnlineCPUs)
-	} else if len(s.CPUStats.CPUUsage.PercpuUsage) > 0 {
-		numCPUs = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
-	} else {
-		numCPUs = 1
+	return (cpuDelta / systemDelta) * onlineCPUs(s) * 100.0
+}
+
+// pidsLimit normalizes a reported pids limit: some runtimes report "max" as
+// the largest integer instead of zero.
+func pidsLimit(limit uint64) uint64 {
+	if limit >= math.MaxInt64 {
+		return 0
+	}
+	return limit
+}
+
+// computeCPUTime returns the container's cumulative CPU time. Linux reports
+// it in nanoseconds, Windows in 100ns intervals.
+func computeCPUTime(s *container.Stats) time.Duration {
+	if isWindowsStats(s) {
+		return time.Duration(s.CPUStats.CPUUsage.TotalUsage) * 100
+	}
+	return time.Duration(s.CPUStats.CPUUsage.TotalUsage)
+}
+
+// onlineCPUs is the number of CPUs in s: OnlineCPUs when present, otherwise
+// len(percpu), at least 1.
+func onlineCPUs(s *container.Stats) float64 {
+	switch {
+	case s.CPUStats.OnlineCPUs > 0:
+		return float64(s.CPUStats.OnlineCPUs)
+	case len(s.CPUStats.CPUUsage.PercpuUsage) > 0:
+		return float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	default:
+		return 1
 	}
-	return (cpuDelta / systemDelta) * numCPUs * 100.0
 }
 
 func computeMemory(s *container.Stats) (usage uint64, limit uint64, percent float64) {
+	if isWindowsStats(s) {
+		// Windows has no memory limit in stats; show the private working set.
+		return s.MemoryStats.PrivateWorkingSet, 0, 0
+	}
 	usage = s.MemoryStats.Usage
 	limit = s.MemoryStats.Limit
 	if limit == 0 || usage == 0 {
@@ -180,6 +654,9 @@ func computeNetwork(s *container.Stats) (rx uint64, tx uint64) {
 }
 
 func computeBlockIO(s *container.Stats) (read uint64, write uint64) {
+	if isWindowsStats(s) {
+		return s.StorageStats.ReadSizeBytes, s.StorageStats.WriteSizeBytes
+	}
 	// Aggregate by operation from BlkioStats.IOServiceBytesRecursive
 	for _, e := range s.BlkioStats.IoServiceBytesRecursive {
 		op := strings.ToLower(e.Op)
@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/client"
+)
+
+// AddSwarmServices adds the node's Swarm services to the network groups:
+// each service is listed under every overlay network it has a virtual IP
+// on, including the ingress network when it publishes ports through the
+// routing mesh. It does nothing outside Swarm mode or on worker nodes,
+// which cannot list services.
+func AddSwarmServices(ctx context.Context, cli *client.Client, groups map[string][]ContainerNetInfo) error {
+	info, err := cli.Info(ctx)
+	if err != nil {
+		return err
+	}
+	if info.Swarm.LocalNodeState != swarm.LocalNodeStateActive || !info.Swarm.ControlAvailable {
+		return nil
+	}
+	nets, err := cli.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(nets))
+	ingress := map[string]bool{}
+	for _, n := range nets {
+		names[n.ID] = n.Name
+		ingress[n.ID] = n.Ingress
+	}
+	services, err := cli.ServiceList(ctx, swarm.ServiceListOptions{Status: true})
+	if err != nil {
+		return err
+	}
+	touched := map[string]bool{}
+	for _, svc := range services {
+		tasks := "service"
+		if st := svc.ServiceStatus; st != nil {
+			tasks = fmt.Sprintf("service %d/%d running", st.RunningTasks, st.DesiredTasks)
+			if st.RunningTasks < st.DesiredTasks {
+				tasks += " (degraded)"
+			}
+		}
+		var published []string
+		for _, p := range svc.Endpoint.Ports {
+			if p.PublishMode == swarm.PortConfigPublishModeIngress {
+				published = append(published, fmt.Sprintf(":%d→%d/%s", p.PublishedPort, p.TargetPort, p.Protocol))
+			}
+		}
+		var onNets []string
+		for _, vip := range svc.Endpoint.VirtualIPs {
+			if name := names[vip.NetworkID]; name != "" {
+				onNets = append(onNets, name)
+			}
+		}
+		for _, vip := range svc.Endpoint.VirtualIPs {
+			name := names[vip.NetworkID]
+			if name == "" {
+				continue
+			}
+			status := tasks
+			if ingress[vip.NetworkID] && len(published) > 0 {
+				status += ", ingress " + strings.Join(published, " ")
+			}
+			groups[name] = append(groups[name], ContainerNetInfo{
+				ID:       svc.ID,
+				Name:     svc.Spec.Name,
+				Status:   status,
+				Networks: onNets,
+				Service:  true,
+			})
+			touched[name] = true
+		}
+	}
+	// Services after the local containers, by name.
+	for n := range touched {
+		sort.SliceStable(groups[n], func(i, j int) bool {
+			a, b := groups[n][i], groups[n][j]
+			if a.Service != b.Service {
+				return !a.Service
+			}
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		})
+	}
+	return nil
+}
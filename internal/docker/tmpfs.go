@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// FillTmpfsUsage adds tmpfs mounts declared with --tmpfs (which the list API
+// omits) and measures how much of each tmpfs running containers use. tmpfs
+// pages count against the container's memory limit, so a full tmpfs can
+// OOM-kill a container that otherwise looks idle.
+//
+// Usage is read with statfs through /proc/<pid>/root when whale runs on the
+// Docker host (Linux), and otherwise by running `df` in the container.
+func FillTmpfsUsage(ctx context.Context, cli *client.Client, list []ContainerMounts) {
+	idx := make([]int, 0, len(list))
+	for i := range list {
+		if list[i].State == "running" {
+			idx = append(idx, i)
+		}
+	}
+	sem := make(chan struct{}, 8)
+	runBounded(idx, func() { sem <- struct{}{} }, func(_ time.Duration, _ error) { <-sem }, func(_, i int) error {
+		fillTmpfs(ctx, cli, &list[i])
+		return nil
+	}, nil)
+}
+
+func fillTmpfs(ctx context.Context, cli *client.Client, c *ContainerMounts) {
+	info, err := cli.ContainerInspect(ctx, c.ID)
+	if err != nil || info.State == nil {
+		return
+	}
+	if info.HostConfig != nil {
+		for dest := range info.HostConfig.Tmpfs {
+			if !hasMount(c.Mounts, dest) {
+				c.Mounts = append(c.Mounts, Mount{Type: "tmpfs", Destination: dest, RW: true})
+			}
+		}
+		sort.Slice(c.Mounts, func(i, j int) bool { return c.Mounts[i].Destination < c.Mounts[j].Destination })
+	}
+	var viaDF []*Mount
+	for j := range c.Mounts {
+		m := &c.Mounts[j]
+		if m.Type != "tmpfs" {
+			continue
+		}
+		if used, total, ok := statfsUsage(info.State.Pid, m.Destination); ok {
+			m.Size, m.Limit = &used, total
+			continue
+		}
+		viaDF = append(viaDF, m)
+	}
+	if len(viaDF) > 0 {
+		dfUsage(ctx, cli, c.ID, viaDF)
+	}
+}
+
+func hasMount(mounts []Mount, dest string) bool {
+	for _, m := range mounts {
+		if m.Destination == dest {
+			return true
+		}
+	}
+	return false
+}
+
+// dfUsage runs `df -Pk` in the container for the given mounts. Images
+// without df are left unmeasured.
+func dfUsage(ctx context.Context, cli *client.Client, id string, mounts []*Mount) {
+	cmd := []string{"df", "-Pk"}
+	for _, m := range mounts {
+		cmd = append(cmd, m.Destination)
+	}
+	created, err := cli.ContainerExecCreate(ctx, id, container.ExecOptions{Cmd: cmd, AttachStdout: true})
+	if err != nil {
+		return
+	}
+	resp, err := cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return
+	}
+	defer resp.Close()
+	var out bytes.Buffer
+	if _, err := stdcopy.StdCopy(&out, &bytes.Buffer{}, resp.Reader); err != nil {
+		return
+	}
+	// Filesystem 1024-blocks Used Available Capacity Mounted-on
+	sc := bufio.NewScanner(&out)
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) < 6 {
+			continue
+		}
+		total, err1 := strconv.ParseInt(f[1], 10, 64)
+		used, err2 := strconv.ParseInt(f[2], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue // header
+		}
+		for _, m := range mounts {
+			if m.Destination == f[5] {
+				used := used * 1024
+				m.Size, m.Limit = &used, total*1024
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package docker
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// statfsUsage measures the filesystem mounted at dest inside the container
+// whose init process is pid, through /proc/<pid>/root. It needs to run on
+// the Docker host with access to the process.
+func statfsUsage(pid int, dest string) (used, total int64, ok bool) {
+	if pid <= 0 {
+		return 0, 0, false
+	}
+	var st unix.Statfs_t
+	if err := unix.Statfs(filepath.Join("/proc", strconv.Itoa(pid), "root", dest), &st); err != nil {
+		return 0, 0, false
+	}
+	if st.Type != unix.TMPFS_MAGIC {
+		// Not a tmpfs as seen from here; let df measure it instead.
+		return 0, 0, false
+	}
+	bsize := int64(st.Bsize)
+	return int64(st.Blocks-st.Bfree) * bsize, int64(st.Blocks) * bsize, true
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package docker
+
+// statfsUsage needs procfs; outside Linux tmpfs usage comes from df.
+func statfsUsage(pid int, dest string) (used, total int64, ok bool) {
+	return 0, 0, false
+}
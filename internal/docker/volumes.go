@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// VolumeSizes returns the bytes used by each named volume, keyed by volume
+// name. Sizes come from the daemon's disk usage report; volumes it reports
+// no size for are measured by walking their mountpoint, which only works
+// when whale runs on the Docker host with access to the data root. Volumes
+// that can't be measured are left out.
+func VolumeSizes(ctx context.Context, cli *client.Client) (map[string]int64, error) {
+	du, err := cli.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(du.Volumes))
+	for _, v := range du.Volumes {
+		if v == nil {
+			continue
+		}
+		if v.UsageData != nil && v.UsageData.Size >= 0 {
+			sizes[v.Name] = v.UsageData.Size
+			continue
+		}
+		if n, ok := dirSize(ctx, v.Mountpoint); ok {
+			sizes[v.Name] = n
+		}
+	}
+	return sizes, nil
+}
+
+// dirSize sums regular file sizes under dir, like `du --apparent-size`.
+func dirSize(ctx context.Context, dir string) (int64, bool) {
+	if dir == "" {
+		return 0, false
+	}
+	var total int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err == nil
+}
+
+// ApplyVolumeSizes sets Size on every named-volume mount found in sizes and
+// orders list by total volume size, largest first, so the containers owning
+// the most data lead.
+func ApplyVolumeSizes(list []ContainerMounts, sizes map[string]int64) {
+	for i := range list {
+		for j := range list[i].Mounts {
+			m := &list[i].Mounts[j]
+			if m.Type != "volume" || m.Name == "" {
+				continue
+			}
+			if n, ok := sizes[m.Name]; ok {
+				m.Size = &n
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, _ := list[i].VolumeBytes()
+		b, _ := list[j].VolumeBytes()
+		return a > b
+	})
+}
@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// ComposeProjectLabel is the label Docker Compose puts on every container of a
+// project; `project=NAME` filters are shorthand for it.
+const ComposeProjectLabel = "com.docker.compose.project"
+
+// ReadyStatus describes one container checked by CheckReady.
+type ReadyStatus struct {
+	ID     string
+	Name   string
+	State  string
+	Health string // "healthy", "unhealthy", "starting", or "" without a healthcheck
+	Ready  bool
+	Reason string // why the container is not ready yet
+}
+
+// ParseFilters turns key=value specs into Docker list filters. Keys are
+// passed through as Docker understands them (name, label, id, ...), except
+// project=NAME, which matches the Compose project label.
+func ParseFilters(specs []string) (filters.Args, error) {
+	args := filters.NewArgs()
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		if !ok || key == "" {
+			return args, fmt.Errorf("invalid filter %q (want key=value)", spec)
+		}
+		if key == "project" {
+			key, value = "label", ComposeProjectLabel+"="+value
+		}
+		args.Add(key, value)
+	}
+	return args, nil
+}
+
+// CheckReady lists every container (running or not) matching f, through l,
+// and reports whether each is running and, when requireHealthy is set,
+// passing its healthcheck. Containers without a healthcheck count as healthy
+// once running.
+func CheckReady(ctx context.Context, cli *client.Client, l *Lister, f filters.Args, requireHealthy bool) ([]ReadyStatus, error) {
+	containers, err := listContainers(ctx, cli, l, true, f)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]ReadyStatus, len(containers))
+	running := make([]int, 0, len(containers))
+	for i, c := range containers {
+		statuses[i] = ReadyStatus{ID: c.ID, Name: deriveName(c.Names), State: c.State}
+		if c.State == "running" {
+			running = append(running, i)
+		}
+	}
+	if requireHealthy {
+		sem := make(chan struct{}, 8)
+		runBounded(running, func() { sem <- struct{}{} }, func(time.Duration, error) { <-sem }, func(_, i int) error {
+			cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			info, err := cli.ContainerInspect(cctx, statuses[i].ID)
+			if err != nil {
+				return err
+			}
+			if info.ContainerJSONBase != nil && info.State != nil && info.State.Health != nil {
+				statuses[i].Health = info.State.Health.Status
+			}
+			return nil
+		}, nil)
+	}
+	for i := range statuses {
+		st := &statuses[i]
+		switch {
+		case st.State != "running":
+			st.Reason = "not running (" + st.State + ")"
+		case requireHealthy && st.Health == "unhealthy":
+			st.Reason = "healthcheck failing"
+		case requireHealthy && st.Health == "starting":
+			st.Reason = "healthcheck starting"
+		default:
+			st.Ready = true
+		}
+	}
+	return statuses, nil
+}
@@ -0,0 +1,622 @@
+// Package filter implements the small expression language behind --where,
+// e.g. `cpu_percent > 20 && has_label("env", "prod")`.
+//
+// Grammar (lowest to highest precedence):
+//
+//	expr    = or
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | compare
+//	compare = operand [ ("==" | "!=" | "<" | "<=" | ">" | ">=" | "=~") operand ]
+//	operand = number | string | "true" | "false" | field | call | "(" expr ")" | "-" operand
+//
+// Numbers accept IEC size suffixes (KiB, MiB, GiB, TiB) and a leading minus.
+// "=~" matches a regular expression.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// Expr is a compiled --where expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses src into an expression.
+func Compile(src string) (*Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("where: unexpected %q at offset %d", p.peek().text, p.peek().pos)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Match reports whether s satisfies the expression.
+func (e *Expr) Match(s dkr.ContainerSnapshot) (bool, error) {
+	v, err := e.root.eval(&s)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("where: %q is not a condition", e.src)
+	}
+	return b, nil
+}
+
+// Filter returns the snapshots that match, preserving order. Evaluation
+// errors (e.g. comparing a string to a number) are returned immediately.
+func (e *Expr) Filter(snaps []dkr.ContainerSnapshot) ([]dkr.ContainerSnapshot, error) {
+	out := snaps[:0:0]
+	for _, s := range snaps {
+		ok, err := e.Match(s)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// fields maps identifiers to snapshot values.
+var fields = map[string]func(s *dkr.ContainerSnapshot) any{
+	"id":          func(s *dkr.ContainerSnapshot) any { return s.ID },
+	"name":        func(s *dkr.ContainerSnapshot) any { return s.Name },
+	"status":      func(s *dkr.ContainerSnapshot) any { return s.Status },
+	"command":     func(s *dkr.ContainerSnapshot) any { return s.Command },
+	"cpu_percent": func(s *dkr.ContainerSnapshot) any { return s.CPUPercent },
+	"mem_usage":   func(s *dkr.ContainerSnapshot) any { return float64(s.MemUsage) },
+	"mem_limit":   func(s *dkr.ContainerSnapshot) any { return float64(s.MemLimit) },
+	"mem_percent": func(s *dkr.ContainerSnapshot) any { return s.MemPercent },
+	"net_rx":      func(s *dkr.ContainerSnapshot) any { return float64(s.NetRx) },
+	"net_tx":      func(s *dkr.ContainerSnapshot) any { return float64(s.NetTx) },
+	"block_read":  func(s *dkr.ContainerSnapshot) any { return float64(s.BlockRead) },
+	"block_write": func(s *dkr.ContainerSnapshot) any { return float64(s.BlockWrite) },
+	"pids":        func(s *dkr.ContainerSnapshot) any { return float64(s.PIDs) },
+	"pids_limit":  func(s *dkr.ContainerSnapshot) any { return float64(s.PIDsLimit) },
+	"cpu_seconds": func(s *dkr.ContainerSnapshot) any { return s.CPUTime.Seconds() },
+	"state":       func(s *dkr.ContainerSnapshot) any { return s.State },
+	// Short aliases.
+	"cpu": func(s *dkr.ContainerSnapshot) any { return s.CPUPercent },
+	"mem": func(s *dkr.ContainerSnapshot) any { return s.MemPercent },
+}
+
+// funcs are the callable helpers. Arguments are already evaluated.
+var funcs = map[string]func(s *dkr.ContainerSnapshot, args []any) (any, error){
+	// has_label(key) or has_label(key, value)
+	"has_label": func(s *dkr.ContainerSnapshot, args []any) (any, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, fmt.Errorf("has_label takes 1 or 2 arguments")
+		}
+		key, err := asString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		v, ok := s.Labels[key]
+		if !ok || len(args) == 1 {
+			return ok, nil
+		}
+		want, err := asString(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return v == want, nil
+	},
+	// label(key) returns the label value or "".
+	"label": func(s *dkr.ContainerSnapshot, args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("label takes 1 argument")
+		}
+		key, err := asString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return s.Labels[key], nil
+	},
+	// contains(haystack, needle), case-insensitive.
+	"contains": func(_ *dkr.ContainerSnapshot, args []any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("contains takes 2 arguments")
+		}
+		h, err := asString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		n, err := asString(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return strings.Contains(strings.ToLower(h), strings.ToLower(n)), nil
+	},
+}
+
+// ---- evaluation ----
+
+type node interface {
+	eval(s *dkr.ContainerSnapshot) (any, error)
+}
+
+type literal struct{ v any }
+
+func (n literal) eval(*dkr.ContainerSnapshot) (any, error) { return n.v, nil }
+
+type fieldRef struct{ name string }
+
+func (n fieldRef) eval(s *dkr.ContainerSnapshot) (any, error) { return fields[n.name](s), nil }
+
+type call struct {
+	name string
+	args []node
+}
+
+func (n call) eval(s *dkr.ContainerSnapshot) (any, error) {
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return funcs[n.name](s, args)
+}
+
+type neg struct{ x node }
+
+func (n neg) eval(s *dkr.ContainerSnapshot) (any, error) {
+	v, err := n.x.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("where: cannot negate %T", v)
+	}
+	return -f, nil
+}
+
+type not struct{ x node }
+
+func (n not) eval(s *dkr.ContainerSnapshot) (any, error) {
+	v, err := n.x.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	b, err := asBool(v)
+	if err != nil {
+		return nil, err
+	}
+	return !b, nil
+}
+
+type logical struct {
+	op   string // "&&" or "||"
+	l, r node
+}
+
+func (n logical) eval(s *dkr.ContainerSnapshot) (any, error) {
+	lv, err := n.l.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	lb, err := asBool(lv)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit like Go.
+	if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+		return lb, nil
+	}
+	rv, err := n.r.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	return asBool(rv)
+}
+
+type compare struct {
+	op   string
+	l, r node
+	re   *regexp.Regexp // precompiled for "=~" with a literal pattern
+}
+
+func (n compare) eval(s *dkr.ContainerSnapshot) (any, error) {
+	lv, err := n.l.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := n.r.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "=~" {
+		ls, err := asString(lv)
+		if err != nil {
+			return nil, err
+		}
+		re := n.re
+		if re == nil {
+			pat, err := asString(rv)
+			if err != nil {
+				return nil, err
+			}
+			if re, err = regexp.Compile(pat); err != nil {
+				return nil, fmt.Errorf("where: %w", err)
+			}
+		}
+		return re.MatchString(ls), nil
+	}
+	switch l := lv.(type) {
+	case float64:
+		r, ok := rv.(float64)
+		if !ok {
+			return nil, fmt.Errorf("where: cannot compare number with %T", rv)
+		}
+		switch n.op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		}
+	case string:
+		r, ok := rv.(string)
+		if !ok {
+			return nil, fmt.Errorf("where: cannot compare string with %T", rv)
+		}
+		// String equality is case-insensitive, matching how names are sorted.
+		switch n.op {
+		case "==":
+			return strings.EqualFold(l, r), nil
+		case "!=":
+			return !strings.EqualFold(l, r), nil
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		}
+	case bool:
+		r, ok := rv.(bool)
+		if !ok {
+			return nil, fmt.Errorf("where: cannot compare bool with %T", rv)
+		}
+		switch n.op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		}
+	}
+	return nil, fmt.Errorf("where: operator %s not supported for %T", n.op, lv)
+}
+
+func asBool(v any) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("where: expected condition, got %T", v)
+	}
+	return b, nil
+}
+
+func asString(v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("where: expected string, got %T", v)
+	}
+	return s, nil
+}
+
+// ---- parsing ----
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokKind, text string) error {
+	t := p.next()
+	if t.kind != kind || (text != "" && t.text != text) {
+		return fmt.Errorf("where: expected %q at offset %d, got %q", text, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOp && p.peek().text == "||" {
+		p.next()
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = logical{op: "||", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOp && p.peek().text == "&&" {
+		p.next()
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = logical{op: "&&", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokOp && p.peek().text == "!" {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{x: x}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind != tokOp {
+		return l, nil
+	}
+	switch t.text {
+	case "==", "!=", "<", "<=", ">", ">=", "=~":
+	default:
+		return l, nil
+	}
+	p.next()
+	r, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	c := compare{op: t.text, l: l, r: r}
+	if lit, ok := r.(literal); ok && t.text == "=~" {
+		pat, ok := lit.v.(string)
+		if !ok {
+			return nil, fmt.Errorf("where: =~ needs a string pattern")
+		}
+		if c.re, err = regexp.Compile(pat); err != nil {
+			return nil, fmt.Errorf("where: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		v, err := parseNumber(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("where: bad number %q at offset %d", t.text, t.pos)
+		}
+		return literal{v: v}, nil
+	case tokString:
+		return literal{v: t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literal{v: true}, nil
+		case "false":
+			return literal{v: false}, nil
+		}
+		if p.peek().kind == tokLParen {
+			return p.parseCall(t)
+		}
+		if _, ok := fields[t.text]; !ok {
+			return nil, fmt.Errorf("where: unknown field %q at offset %d", t.text, t.pos)
+		}
+		return fieldRef{name: t.text}, nil
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case tokOp:
+		if t.text == "-" {
+			x, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return neg{x: x}, nil
+		}
+	case tokEOF:
+		return nil, fmt.Errorf("where: unexpected end of expression")
+	}
+	return nil, fmt.Errorf("where: unexpected %q at offset %d", t.text, t.pos)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	if _, ok := funcs[name.text]; !ok {
+		return nil, fmt.Errorf("where: unknown function %q at offset %d", name.text, name.pos)
+	}
+	p.next() // "("
+	c := call{name: name.text}
+	if p.peek().kind == tokRParen {
+		p.next()
+		return c, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+		if p.peek().kind == tokComma {
+			p.next()
+			continue
+		}
+		if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+}
+
+// parseNumber accepts plain numbers and IEC size suffixes (e.g. 512MiB).
+func parseNumber(s string) (float64, error) {
+	mult := 1.0
+	for _, u := range []struct {
+		suffix string
+		mult   float64
+	}{{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSuffix(s, u.suffix), u.mult
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return v * mult, nil
+}
+
+// ---- lexing ----
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case r == ',':
+			toks = append(toks, token{tokComma, ",", i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			var b strings.Builder
+			for i < len(rs) && rs[i] != r {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+				}
+				b.WriteRune(rs[i])
+				i++
+			}
+			if i >= len(rs) {
+				return nil, fmt.Errorf("where: unterminated string at offset %d", start)
+			}
+			i++ // closing quote
+			toks = append(toks, token{tokString, b.String(), start})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			start := i
+			for i < len(rs) && (unicode.IsDigit(rs[i]) || rs[i] == '.' || unicode.IsLetter(rs[i])) {
+				i++
+			}
+			toks = append(toks, token{tokNumber, string(rs[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(rs) && (unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i]) || rs[i] == '_') {
+				i++
+			}
+			toks = append(toks, token{tokIdent, string(rs[start:i]), start})
+		default:
+			start := i
+			two := ""
+			if i+1 < len(rs) {
+				two = string(rs[i : i+2])
+			}
+			switch two {
+			case "&&", "||", "==", "!=", "<=", ">=", "=~":
+				toks = append(toks, token{tokOp, two, start})
+				i += 2
+				continue
+			}
+			switch r {
+			case '<', '>', '!', '-':
+				toks = append(toks, token{tokOp, string(r), start})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("where: unexpected character %q at offset %d", r, start)
+		}
+	}
+	toks = append(toks, token{kind: tokEOF, pos: len(rs)})
+	return toks, nil
+}
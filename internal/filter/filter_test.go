@@ -0,0 +1,155 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+var web = dkr.ContainerSnapshot{
+	Name:       "web-1",
+	Status:     "Up 2 hours",
+	State:      "running",
+	Command:    "w.b",
+	CPUPercent: 12.5,
+	MemUsage:   600 << 20,
+	Labels:     map[string]string{"env": "prod"},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		// Precedence: && binds tighter than ||, ! tighter than &&.
+		{`true || false && false`, true},
+		{`(true || false) && false`, false},
+		{`!false && false`, false},
+		{`!(false && false)`, true},
+		{`cpu > 10 && mem_usage < 1GiB || name == "db"`, true},
+
+		// Short-circuit: the right side would fail to evaluate.
+		{`false && name > 1`, false},
+		{`true || name > 1`, true},
+
+		// =~ with a literal pattern, and with a pattern read from a field.
+		{`name =~ "^web-[0-9]+$"`, true},
+		{`name =~ "^db"`, false},
+		{`name =~ command`, true},
+		{`status =~ command`, false},
+
+		// Size suffixes.
+		{`mem_usage > 512MiB`, true},
+		{`mem_usage >= 600MiB && mem_usage <= 600MiB`, true},
+		{`mem_usage < 0.5GiB`, false},
+
+		// Negative numbers.
+		{`cpu > -1`, true},
+		{`-cpu < -12`, true},
+		{`cpu > --13`, false},
+
+		// Strings compare case-insensitively for equality.
+		{`name == "WEB-1"`, true},
+		{`state != "exited"`, true},
+
+		// Functions.
+		{`has_label("env", "prod")`, true},
+		{`label("env") == "dev"`, false},
+		{`contains(name, "EB")`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := e.Match(web)
+			if err != nil {
+				t.Fatalf("Match: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{`name == "web`, "unterminated string at offset 8"},
+		{`name == 'web`, "unterminated string"},
+		{`name =~ "("`, "missing closing )"},
+		{`name =~ 5`, "=~ needs a string pattern"},
+		{`nope > 1`, `unknown field "nope"`},
+		{`nope(1)`, `unknown function "nope"`},
+		{`cpu > 5XiB`, `bad number "5XiB"`},
+		{`cpu >`, "unexpected end of expression"},
+		{`(cpu > 1`, `expected ")"`},
+		{`cpu > 1 cpu`, `unexpected "cpu"`},
+		{`cpu # 1`, "unexpected character '#'"},
+		{`cpu - 1`, `unexpected "-"`},
+		{`cpu * 2 > 1`, "unexpected character '*'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compile(%q) error = %v, want %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchTypeErrors(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{`name > 1`, "cannot compare string with float64"},
+		{`cpu == "high"`, "cannot compare number with string"},
+		{`true == 1`, "cannot compare bool with float64"},
+		{`true < false`, "operator < not supported for bool"},
+		{`cpu && true`, "expected condition, got float64"},
+		{`!name`, "expected condition, got string"},
+		{`cpu =~ "1"`, "expected string, got float64"},
+		{`name =~ cpu`, "expected string, got float64"},
+		{`-name > 1`, "cannot negate string"},
+		{`cpu`, "is not a condition"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			_, err = e.Match(web)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Match error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	db := web
+	db.Name, db.CPUPercent = "db", 80
+	e, err := Compile(`cpu >= 50`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.Filter([]dkr.ContainerSnapshot{web, db, web})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Name != "db" {
+		t.Errorf("Filter = %v, want only db", got)
+	}
+	if e, err = Compile(`name > 1`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Filter([]dkr.ContainerSnapshot{web}); err == nil {
+		t.Error("Filter with a type error succeeded")
+	}
+}
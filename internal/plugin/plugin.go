@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// Timeout bounds a single plugin invocation.
+const Timeout = 2 * time.Second
+
+// Run executes each column plugin and merges the values it returns into the
+// snapshots' Extra maps.
+//
+// Protocol: the plugin receives the snapshots as a JSON array on stdin (the
+// same shape `whale snapshot` stores, including labels) and prints a JSON
+// object mapping container ID to column values, e.g.
+//
+//	{"3f2a...": {"VERSION": "1.4.2"}}
+//
+// Each distinct key becomes a table column. Unknown IDs are ignored.
+func Run(ctx context.Context, paths []string, snaps []dkr.ContainerSnapshot) error {
+	if len(paths) == 0 || len(snaps) == 0 {
+		return nil
+	}
+	input, err := json.Marshal(snaps)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*dkr.ContainerSnapshot, len(snaps))
+	for i := range snaps {
+		byID[snaps[i].ID] = &snaps[i]
+	}
+	var errs []string
+	for _, p := range paths {
+		values, err := runOne(ctx, p, input)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("plugin %s: %v", p, err))
+			continue
+		}
+		for id, cols := range values {
+			s, ok := byID[id]
+			if !ok {
+				continue
+			}
+			if s.Extra == nil {
+				s.Extra = make(map[string]string, len(cols))
+			}
+			for k, v := range cols {
+				s.Extra[k] = v
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func runOne(ctx context.Context, path string, input []byte) (map[string]map[string]string, error) {
+	cctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	var out map[string]map[string]string
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,403 @@
+// Package scan runs an external vulnerability scanner (trivy or grype) on
+// container images and caches the results per image ID, so the CVES column
+// stays cheap after the first scan. It is opt-in via --scan.
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+const (
+	// Column is the table column the summary is shown in.
+	Column = "CVES"
+	// Timeout bounds a single image scan; the first run of a scanner also
+	// downloads its vulnerability database.
+	Timeout = 10 * time.Minute
+	// TTL is how long a cached result is trusted before rescanning.
+	TTL = 24 * time.Hour
+)
+
+// Severities in the order results are shown.
+var Severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"}
+
+// Vuln is one finding in an image.
+type Vuln struct {
+	ID        string `json:"id"`
+	Severity  string `json:"severity"`
+	Package   string `json:"package"`
+	Installed string `json:"installed"`
+	Fixed     string `json:"fixed,omitempty"`
+}
+
+// Result is a scan of one image.
+type Result struct {
+	Image     string         `json:"image"`
+	ImageID   string         `json:"image_id"`
+	Tool      string         `json:"tool"`
+	ScannedAt time.Time      `json:"scanned_at"`
+	Counts    map[string]int `json:"counts"` // by severity
+	Vulns     []Vuln         `json:"vulns"`
+}
+
+// Summary is the compact CVES cell, e.g. "C2 H5 M12", or the total when
+// there are only low-severity findings.
+func (r *Result) Summary() string {
+	var parts []string
+	for _, sev := range Severities[:3] {
+		if n := r.Counts[sev]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%c%d", sev[0], n))
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d", len(r.Vulns))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Scanner runs Tool and caches results in memory and under CacheDir.
+type Scanner struct {
+	Tool     string // "trivy" or "grype"
+	CacheDir string
+	// Block makes Apply wait for the scans it starts (one-shot runs);
+	// otherwise they finish in the background and show up on a later
+	// refresh.
+	Block bool
+
+	mu      sync.Mutex
+	results map[string]*Result // by image ID
+	pending map[string]bool
+	errs    map[string]error
+	queue   chan job
+}
+
+type job struct{ image, id string }
+
+// New returns a scanner for tool, which must be on PATH.
+func New(tool string) (*Scanner, error) {
+	switch tool {
+	case "trivy", "grype":
+	default:
+		return nil, fmt.Errorf("unknown scanner %q (use trivy or grype)", tool)
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("--scan=%s: %w", tool, err)
+	}
+	dir := ""
+	if base, err := os.UserCacheDir(); err == nil {
+		dir = filepath.Join(base, "whale", "scan")
+	}
+	return &Scanner{
+		Tool:     tool,
+		CacheDir: dir,
+		results:  map[string]*Result{},
+		pending:  map[string]bool{},
+		errs:     map[string]error{},
+	}, nil
+}
+
+// Apply sets the CVES column on running containers. Images without a fresh
+// result are scanned one at a time; until then their cell reads "scanning…".
+// Failed scans are not retried within a run.
+func (s *Scanner) Apply(ctx context.Context, snaps []dkr.ContainerSnapshot) error {
+	var jobs []job
+	s.mu.Lock()
+	for _, snap := range snaps {
+		id := snap.ImageID
+		if id == "" || snap.State != "running" || s.pending[id] || s.errs[id] != nil {
+			continue
+		}
+		if r := s.results[id]; r != nil && time.Since(r.ScannedAt) < TTL {
+			continue
+		}
+		if r := s.loadCached(id); r != nil {
+			s.results[id] = r
+			continue
+		}
+		s.pending[id] = true
+		jobs = append(jobs, job{snap.Image, id})
+	}
+	if !s.Block && len(jobs) > 0 && s.queue == nil {
+		s.queue = make(chan job, 256)
+		go func() {
+			for j := range s.queue {
+				s.run(ctx, j)
+			}
+		}()
+	}
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		if s.Block {
+			s.run(ctx, j)
+		} else {
+			s.queue <- j
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []string
+	for i := range snaps {
+		id := snaps[i].ImageID
+		var cell string
+		switch {
+		case s.results[id] != nil:
+			cell = s.results[id].Summary()
+		case s.pending[id]:
+			cell = "scanning…"
+		case s.errs[id] != nil:
+			cell = "error"
+			errs = append(errs, fmt.Sprintf("%s: %v", snaps[i].Image, s.errs[id]))
+		default:
+			continue
+		}
+		if snaps[i].Extra == nil {
+			snaps[i].Extra = map[string]string{}
+		}
+		snaps[i].Extra[Column] = cell
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("scan: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Scan returns the result for one image, from cache when fresh.
+func (s *Scanner) Scan(ctx context.Context, image, id string) (*Result, error) {
+	s.mu.Lock()
+	if r := s.results[id]; r != nil && time.Since(r.ScannedAt) < TTL {
+		s.mu.Unlock()
+		return r, nil
+	}
+	if r := s.loadCached(id); r != nil {
+		s.results[id] = r
+		s.mu.Unlock()
+		return r, nil
+	}
+	delete(s.errs, id)
+	s.pending[id] = true
+	s.mu.Unlock()
+	s.run(ctx, job{image, id})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.errs[id]; err != nil {
+		return nil, err
+	}
+	return s.results[id], nil
+}
+
+// run scans one image and records the result or error.
+func (s *Scanner) run(ctx context.Context, j job) {
+	r, err := s.exec(ctx, j.image)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, j.id)
+	if err != nil {
+		s.errs[j.id] = err
+		return
+	}
+	r.ImageID = j.id
+	s.results[j.id] = r
+	s.saveCached(r)
+}
+
+func (s *Scanner) exec(ctx context.Context, image string) (*Result, error) {
+	cctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	args := []string{"--quiet", "-o", "json", image}
+	if s.Tool == "trivy" {
+		args = []string{"image", "--quiet", "--format", "json", image}
+	}
+	cmd := exec.CommandContext(cctx, s.Tool, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", s.Tool, err, lastLine(msg))
+		}
+		return nil, fmt.Errorf("%s: %w", s.Tool, err)
+	}
+	parse := parseGrype
+	if s.Tool == "trivy" {
+		parse = parseTrivy
+	}
+	vulns, err := parse(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid output: %w", s.Tool, err)
+	}
+	sortVulns(vulns)
+	r := &Result{Image: image, Tool: s.Tool, ScannedAt: time.Now().UTC(), Counts: map[string]int{}, Vulns: vulns}
+	for _, v := range vulns {
+		r.Counts[v.Severity]++
+	}
+	return r, nil
+}
+
+func parseTrivy(data []byte) ([]Vuln, error) {
+	var out struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID  string
+				PkgName          string
+				InstalledVersion string
+				FixedVersion     string
+				Severity         string
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	var vulns []Vuln
+	for _, res := range out.Results {
+		for _, v := range res.Vulnerabilities {
+			vulns = append(vulns, Vuln{
+				ID:        v.VulnerabilityID,
+				Severity:  normalizeSeverity(v.Severity),
+				Package:   v.PkgName,
+				Installed: v.InstalledVersion,
+				Fixed:     v.FixedVersion,
+			})
+		}
+	}
+	return vulns, nil
+}
+
+func parseGrype(data []byte) ([]Vuln, error) {
+	var out struct {
+		Matches []struct {
+			Vulnerability struct {
+				ID       string `json:"id"`
+				Severity string `json:"severity"`
+				Fix      struct {
+					Versions []string `json:"versions"`
+				} `json:"fix"`
+			} `json:"vulnerability"`
+			Artifact struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"artifact"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	var vulns []Vuln
+	for _, m := range out.Matches {
+		vulns = append(vulns, Vuln{
+			ID:        m.Vulnerability.ID,
+			Severity:  normalizeSeverity(m.Vulnerability.Severity),
+			Package:   m.Artifact.Name,
+			Installed: m.Artifact.Version,
+			Fixed:     strings.Join(m.Vulnerability.Fix.Versions, ", "),
+		})
+	}
+	return vulns, nil
+}
+
+// normalizeSeverity maps both tools' spellings ("Critical", "HIGH",
+// "Negligible") onto Severities.
+func normalizeSeverity(s string) string {
+	s = strings.ToUpper(s)
+	for _, sev := range Severities {
+		if s == sev {
+			return s
+		}
+	}
+	if s == "NEGLIGIBLE" {
+		return "LOW"
+	}
+	return "UNKNOWN"
+}
+
+// severityRank is the position of sev in Severities.
+func severityRank(sev string) int {
+	for i, s := range Severities {
+		if s == sev {
+			return i
+		}
+	}
+	return len(Severities)
+}
+
+// sortVulns orders by severity, then package and ID.
+func sortVulns(vulns []Vuln) {
+	sort.SliceStable(vulns, func(i, j int) bool {
+		a, b := vulns[i], vulns[j]
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra < rb
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.ID < b.ID
+	})
+}
+
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// cachePath names the cache file for an image ID.
+func (s *Scanner) cachePath(id string) string {
+	if s.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(s.CacheDir, s.Tool+"-"+strings.TrimPrefix(id, "sha256:")+".json")
+}
+
+// loadCached returns a fresh cached result for id, or nil. Callers hold mu.
+func (s *Scanner) loadCached(id string) *Result {
+	path := s.cachePath(id)
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil || time.Since(r.ScannedAt) >= TTL {
+		return nil
+	}
+	return &r
+}
+
+// saveCached writes r to the cache directory. Callers hold mu. Failures
+// only cost a rescan next time.
+func (s *Scanner) saveCached(r *Result) {
+	path := s.cachePath(r.ImageID)
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
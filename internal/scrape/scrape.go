@@ -0,0 +1,211 @@
+// Package scrape reads a few whitelisted Prometheus metrics from containers
+// that opt in with labels, so app-level numbers can sit next to infra stats.
+//
+// A container opts in with:
+//
+//	whale.metrics.port=9100          (required)
+//	whale.metrics.path=/metrics      (optional, default /metrics)
+package scrape
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+const (
+	LabelPort = "whale.metrics.port"
+	LabelPath = "whale.metrics.path"
+
+	// Timeout bounds each inspect + scrape round trip.
+	Timeout = time.Second
+	// concurrency bounds parallel scrapes per refresh.
+	concurrency = 8
+)
+
+// Scraper fetches metrics and remembers the previous values so counters
+// (names ending in _total) can be shown as per-second rates in watch mode.
+type Scraper struct {
+	cli     *client.Client
+	http    *http.Client
+	metrics []string
+
+	mu   sync.Mutex
+	prev map[string]sample // by container ID
+}
+
+type sample struct {
+	at   time.Time
+	vals map[string]float64
+}
+
+// New returns a scraper for the given metric names.
+func New(cli *client.Client, metrics []string) *Scraper {
+	return &Scraper{
+		cli:     cli,
+		http:    &http.Client{Timeout: Timeout},
+		metrics: metrics,
+		prev:    map[string]sample{},
+	}
+}
+
+// Scrape fills Extra columns on opted-in snapshots. Per-container failures
+// leave the cells blank; only the first error is returned, for reporting.
+func (s *Scraper) Scrape(ctx context.Context, snaps []dkr.ContainerSnapshot) error {
+	if len(s.metrics) == 0 {
+		return nil
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var firstErr error
+	seen := map[string]struct{}{}
+	for i := range snaps {
+		snap := &snaps[i]
+		seen[snap.ID] = struct{}{}
+		port := snap.Labels[LabelPort]
+		if port == "" {
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := s.scrapeOne(ctx, snap, port); err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("metrics %s: %w", snap.Name, err)
+				}
+				errMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	s.mu.Lock()
+	for id := range s.prev {
+		if _, ok := seen[id]; !ok {
+			delete(s.prev, id)
+		}
+	}
+	s.mu.Unlock()
+	return firstErr
+}
+
+func (s *Scraper) scrapeOne(ctx context.Context, snap *dkr.ContainerSnapshot, port string) error {
+	cctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	host, err := dkr.ContainerIP(cctx, s.cli, snap.ID)
+	if err != nil {
+		return err
+	}
+	path := snap.Labels[LabelPath]
+	if path == "" {
+		path = "/metrics"
+	}
+	url := "http://" + net.JoinHostPort(host, port) + path
+	req, err := http.NewRequestWithContext(cctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	vals, err := parse(io.LimitReader(resp.Body, 8*1024*1024), s.metrics)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	prev, hasPrev := s.prev[snap.ID]
+	s.prev[snap.ID] = sample{at: now, vals: vals}
+	s.mu.Unlock()
+
+	if snap.Extra == nil {
+		snap.Extra = map[string]string{}
+	}
+	for _, m := range s.metrics {
+		v, ok := vals[m]
+		if !ok {
+			continue
+		}
+		if strings.HasSuffix(m, "_total") {
+			// Counters: show a rate once a previous sample exists.
+			pv, ok := prev.vals[m]
+			if hasPrev && ok && v >= pv {
+				if dt := now.Sub(prev.at).Seconds(); dt > 0 {
+					snap.Extra[m] = fmt.Sprintf("%.1f/s", (v-pv)/dt)
+					continue
+				}
+			}
+		}
+		snap.Extra[m] = formatValue(v)
+	}
+	return nil
+}
+
+// parse reads Prometheus text exposition format and sums the samples of each
+// wanted metric across label sets.
+func parse(r io.Reader, wanted []string) (map[string]float64, error) {
+	want := make(map[string]bool, len(wanted))
+	for _, w := range wanted {
+		want[w] = true
+	}
+	out := map[string]float64{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		name := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name = line[:i]
+		}
+		if !want[name] {
+			continue
+		}
+		rest := line[len(name):]
+		if strings.HasPrefix(rest, "{") {
+			end := strings.LastIndex(rest, "}")
+			if end < 0 {
+				continue
+			}
+			rest = rest[end+1:]
+		}
+		f := strings.Fields(rest)
+		if len(f) == 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(f[0], 64)
+		if err != nil {
+			continue
+		}
+		out[name] += v
+	}
+	return out, sc.Err()
+}
+
+func formatValue(v float64) string {
+	if v == float64(int64(v)) {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
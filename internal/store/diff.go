@@ -0,0 +1,81 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// Find returns the most recent snapshot labeled tag.
+func Find(snaps []Snapshot, tag string) (Snapshot, error) {
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if snaps[i].Tag == tag {
+			return snaps[i], nil
+		}
+	}
+	return Snapshot{}, fmt.Errorf("no snapshot tagged %q", tag)
+}
+
+// Change is one container compared across two snapshots. Before is nil for
+// a container that appeared, After for one that went away.
+type Change struct {
+	Name   string                 `json:"name"`
+	Before *dkr.ContainerSnapshot `json:"before,omitempty"`
+	After  *dkr.ContainerSnapshot `json:"after,omitempty"`
+}
+
+// Added reports whether the container only exists in the later snapshot.
+func (c Change) Added() bool { return c.Before == nil }
+
+// Removed reports whether the container only exists in the earlier snapshot.
+func (c Change) Removed() bool { return c.After == nil }
+
+// Recreated reports whether a container of the same name exists in both
+// snapshots but with a different ID, so its counters restarted from zero.
+func (c Change) Recreated() bool {
+	return c.Before != nil && c.After != nil && c.Before.ID != c.After.ID
+}
+
+// Diff pairs the containers of a and b by name (IDs change when Compose
+// recreates a service), removed and added ones first, then the rest by name.
+func Diff(a, b Snapshot) []Change {
+	byName := map[string]*Change{}
+	var names []string
+	get := func(name string) *Change {
+		if c := byName[name]; c != nil {
+			return c
+		}
+		c := &Change{Name: name}
+		byName[name] = c
+		names = append(names, name)
+		return c
+	}
+	for i := range a.Containers {
+		get(a.Containers[i].Name).Before = &a.Containers[i]
+	}
+	for i := range b.Containers {
+		get(b.Containers[i].Name).After = &b.Containers[i]
+	}
+	out := make([]Change, 0, len(names))
+	for _, n := range names {
+		out = append(out, *byName[n])
+	}
+	rank := func(c Change) int {
+		switch {
+		case c.Removed():
+			return 0
+		case c.Added():
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if ri, rj := rank(out[i]), rank(out[j]); ri != rj {
+			return ri < rj
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// DefaultPath is the snapshot file used when no --store is given.
+const DefaultPath = "whale-snapshots.jsonl"
+
+// Snapshot is a labeled point-in-time capture of all container metrics.
+type Snapshot struct {
+	Tag        string                  `json:"tag"`
+	Time       time.Time               `json:"time"`
+	Containers []dkr.ContainerSnapshot `json:"containers"`
+}
+
+// Append writes s as a single JSON line at the end of the file at path,
+// creating the file if needed. One line per snapshot keeps appends atomic
+// enough for cron/CI use and the file greppable.
+func Append(path string, s Snapshot) error {
+	if path == "" {
+		path = DefaultPath
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads all snapshots from path in the order they were appended.
+// A missing file yields no snapshots and no error.
+func Load(path string) ([]Snapshot, error) {
+	if path == "" {
+		path = DefaultPath
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Snapshot
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // large hosts produce long lines
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var s Snapshot
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		out = append(out, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
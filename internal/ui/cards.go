@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// Layout selects how the container list is drawn in table format.
+type Layout string
+
+const (
+	// LayoutAuto uses the table, or cards on terminals narrower than
+	// CompactWidth.
+	LayoutAuto  Layout = "auto"
+	LayoutTable Layout = "table"
+	LayoutCards Layout = "cards"
+)
+
+// CompactWidth is the terminal width below which LayoutAuto switches to cards.
+const CompactWidth = 80
+
+// useCards decides between the table and the card layout for w.
+func useCards(layout Layout, w io.Writer) bool {
+	switch layout {
+	case LayoutCards:
+		return true
+	case LayoutTable:
+		return false
+	}
+	width := detectTerminalWidth(w)
+	return width > 0 && width < CompactWidth
+}
+
+// renderCards prints one block of lines per container instead of a table,
+// which stays readable on narrow terminals:
+//
+//	web-frontend  Up 2 hours  0123456789ab
+//	  CPU 12.5%  MEM 256.00MiB / 1.00GiB 25.0%  PIDS 12
+//	  NET 1.00MiB / 512.00KiB  BLOCK 4.00MiB / 0B
+func renderCards(snaps []dkr.ContainerSnapshot, opts RenderOptions, w io.Writer) {
+	title := listTitle(snaps, opts)
+	if opts.Sort == SortName {
+		// Names carry no label to mark.
+		title += " — " + sortMark("by name", SortName, opts.Sort)
+	}
+	fmt.Fprintln(w, text.Colors{text.FgHiWhite, text.Bold}.Sprint(title))
+	if len(snaps) == 0 {
+		fmt.Fprintln(w, "no containers")
+		return
+	}
+	label := text.Colors{text.Faint}
+	extras := extraColumns(snaps, opts)
+	for _, s := range snaps {
+		fmt.Fprintln(w)
+		name := styleName(s, text.Colors{text.Bold}.Sprint(s.Name))
+		if opts.Selected != "" && s.ID == opts.Selected {
+			name = text.Colors{text.ReverseVideo}.Sprint(name)
+		}
+		fmt.Fprintf(w, "%s  %s  %s\n", name, statusCell(s), label.Sprint(TruncateID(s.ID, opts.NoTrunc)))
+		if s.State == "running" && !s.StatsUnavailable && !strings.EqualFold(s.Status, "ERROR") {
+			mem := "—"
+			switch {
+			case s.MemLimit > 0:
+				mem = fmt.Sprintf("%s / %s %s", HumanizeBytes(s.MemUsage), HumanizeBytes(s.MemLimit),
+					formatPercent(dashIfZeroPercent(s.MemPercent)+"%", s.MemPercent, 0))
+			case s.MemUsage > 0:
+				// No limit reported (Windows containers).
+				mem = HumanizeBytes(s.MemUsage)
+			}
+			pids := pidsCell(s)
+			cpu := formatPercent(dashIfZeroPercent(s.CPUPercent), scaledCPU(s, opts), 0)
+			if cpu != "—" {
+				cpu += "%"
+			}
+			cpu, mem = anomalyMark(cpu, s.CPUAnomaly), anomalyMark(mem, s.MemAnomaly)
+			mem = memFullInMark(mem, s.MemFullIn)
+			netIO, blkIO := printableIO(s.NetRx, s.NetTx), printableIO(s.BlockRead, s.BlockWrite)
+			if opts.ShowRates {
+				netIO = printableRate(s.NetRxRate, s.NetTxRate)
+				blkIO = printableRate(s.BlockReadRate, s.BlockWriteRate)
+			}
+			fmt.Fprintf(w, "  %s %s  %s %s  %s %s\n", label.Sprint(sortMark("CPU", SortCPU, opts.Sort)), cpu, label.Sprint(sortMark("MEM", SortMem, opts.Sort)), mem, label.Sprint("PIDS"), pids)
+			netIO = hostNetCell(s, netIO)
+			fmt.Fprintf(w, "  %s %s  %s %s\n", label.Sprint(sortMark("NET", SortNetRate, opts.Sort)), netIO, label.Sprint(sortMark("BLOCK", SortBlockRate, opts.Sort)), blkIO)
+		}
+		for _, c := range extras {
+			if v := c.cell(s, c.width); v != "" {
+				header := c.header
+				if k, ok := sortHeaders[header]; ok {
+					header = sortMark(header, k, opts.Sort)
+				}
+				fmt.Fprintf(w, "  %s %s\n", label.Sprint(header), v)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// csvHeader names the columns of the csv format. Values are raw numbers
+// (bytes, percent) so spreadsheets can sum and chart them.
+var csvHeader = []string{
+	"name", "id", "state", "status", "image",
+	"cpu_percent", "mem_usage", "mem_limit", "mem_percent",
+	"net_rx", "net_tx", "block_read", "block_write", "pids", "collected_at",
+}
+
+func renderCSV(w io.Writer, snaps []dkr.ContainerSnapshot, _ RenderOptions) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	u := func(v uint64) string { return strconv.FormatUint(v, 10) }
+	f := func(v float64) string { return strconv.FormatFloat(round1(v), 'f', -1, 64) }
+	for _, s := range snaps {
+		if err := cw.Write([]string{
+			s.Name, s.ID, s.State, s.Status, s.Image,
+			f(s.CPUPercent), u(s.MemUsage), u(s.MemLimit), f(s.MemPercent),
+			u(s.NetRx), u(s.NetTx), u(s.BlockRead), u(s.BlockWrite),
+			strconv.Itoa(s.PIDs), s.CollectedAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// DetailHistory is the rolling history charted by RenderContainerDetail,
+// oldest reading first, one reading per Interval.
+type DetailHistory struct {
+	Interval time.Duration
+	CPU      []float64 // CPU %
+	Mem      []float64 // bytes
+}
+
+// chartHeight is the height of each chart in lines; braille gives four dot
+// rows per line.
+const chartHeight = 5
+
+// chartAxisWidth is the space left of a chart for its axis labels.
+const chartAxisWidth = 12
+
+// RenderContainerDetail prints one container's card followed by rolling CPU
+// and memory charts in braille, sized to the terminal width. The CPU chart
+// tops out at the capacity CPUScale picks (100% by default) or the peak, the memory chart at
+// the container's limit, or its peak when it has none.
+func RenderContainerDetail(w io.Writer, s dkr.ContainerSnapshot, hist DetailHistory, opts RenderOptions) {
+	renderCards([]dkr.ContainerSnapshot{s}, opts, w)
+	width := detectTerminalWidth(w)
+	if width <= 0 {
+		width = 80
+	}
+	cols := max(width-chartAxisWidth, 10)
+	span := ""
+	if n := min(len(hist.CPU), cols*2); n > 1 && hist.Interval > 0 {
+		span = fmt.Sprintf(", last %s", (time.Duration(n-1) * hist.Interval).Round(time.Second))
+	}
+
+	cpuTop := math.Max(cpuCapacity(s, opts)*100, peak(hist.CPU))
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%s\n", text.Colors{text.Faint}.Sprint("CPU %"), text.Colors{text.Faint}.Sprint(span))
+	writeChart(w, hist.CPU, cpuTop, cols, text.Colors{text.FgGreen}, func(v float64) string { return fmt.Sprintf("%.0f%%", v) })
+
+	memTop := float64(s.MemLimit)
+	if memTop <= 0 {
+		// No limit reported (Windows containers): scale to what was seen.
+		memTop = peak(hist.Mem)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%s\n", text.Colors{text.Faint}.Sprint("MEM"), text.Colors{text.Faint}.Sprint(span))
+	writeChart(w, hist.Mem, memTop, cols, text.Colors{text.FgCyan}, func(v float64) string { return HumanizeBytes(uint64(v)) })
+}
+
+// writeChart prints values as a chartHeight-line braille area chart, cols
+// characters wide, labelling the top and bottom of the axis.
+func writeChart(w io.Writer, values []float64, top float64, cols int, color text.Colors, label func(float64) string) {
+	lines := brailleChart(values, top, cols, chartHeight)
+	for i, line := range lines {
+		axis := ""
+		switch i {
+		case 0:
+			axis = label(top)
+		case len(lines) - 1:
+			axis = label(0)
+		}
+		fmt.Fprintf(w, "%*s ┤%s\n", chartAxisWidth-2, axis, color.Sprint(line))
+	}
+}
+
+// brailleChart draws the last 2*cols values as an area chart of height
+// lines, newest on the right. Each braille cell holds two readings side by
+// side and four levels, so the chart resolves height*4 steps up to top.
+// Values above top are clipped; any value above zero shows at least one dot.
+func brailleChart(values []float64, top float64, cols, height int) []string {
+	// Dot bits of a braille cell by column and row, top row first.
+	bits := [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+	if len(values) > cols*2 {
+		values = values[len(values)-cols*2:]
+	}
+	levels := height * 4
+	// Right-align: the first offset dot columns stay empty.
+	offset := cols*2 - len(values)
+	level := func(x int) int {
+		if x < offset || top <= 0 {
+			return 0
+		}
+		v := values[x-offset]
+		if v <= 0 {
+			return 0
+		}
+		return min(max(int(math.Round(v/top*float64(levels))), 1), levels)
+	}
+	lines := make([]string, height)
+	for r := range height {
+		var b strings.Builder
+		for c := range cols {
+			cell := rune(0x2800)
+			for dx := range 2 {
+				l := level(c*2 + dx)
+				for dy := range 4 {
+					// Dot rows counted from the bottom of the chart.
+					if (height-1-r)*4+(3-dy) < l {
+						cell |= bits[dx][dy]
+					}
+				}
+			}
+			b.WriteRune(cell)
+		}
+		lines[r] = b.String()
+	}
+	return lines
+}
+
+func peak(values []float64) float64 {
+	m := 0.0
+	for _, v := range values {
+		m = math.Max(m, v)
+	}
+	return m
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	prettytable "github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+	"github.com/therapys/whale/internal/store"
+)
+
+// RenderDiff renders the changes between two tagged snapshots: containers
+// that appeared or went away, and for the others how CPU, memory, status
+// and image moved and how much I/O happened in between.
+func RenderDiff(a, b store.Snapshot, changes []store.Change, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			From    string         `json:"from"`
+			FromAt  time.Time      `json:"from_time"`
+			To      string         `json:"to"`
+			ToAt    time.Time      `json:"to_time"`
+			Changes []store.Change `json:"changes"`
+		}{a.Tag, a.Time, b.Tag, b.Time, changes})
+	}
+	tw := newScanTable(w)
+	added, removed := 0, 0
+	for _, c := range changes {
+		switch {
+		case c.Added():
+			added++
+		case c.Removed():
+			removed++
+		}
+	}
+	tw.SetTitle(fmt.Sprintf("whale — %s (%s) → %s (%s): +%d added, −%d removed",
+		a.Tag, inZone(a.Time).Format("Jan 2 15:04"), b.Tag, inZone(b.Time).Format("Jan 2 15:04"), added, removed))
+	tw.AppendHeader(prettytable.Row{"NAME", "CHANGE", "STATUS", "CPU %", "MEM", "NET I/O", "BLOCK I/O", "IMAGE"})
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "NAME", WidthMax: 25},
+		{Name: "CHANGE", WidthMax: 9},
+		{Name: "STATUS", WidthMax: 30},
+		{Name: "CPU %", WidthMax: 22},
+		{Name: "MEM", WidthMax: 36},
+		{Name: "NET I/O", WidthMax: 22},
+		{Name: "BLOCK I/O", WidthMax: 22},
+		{Name: "IMAGE", WidthMax: 40},
+	})
+	if len(changes) == 0 {
+		tw.AppendFooter(prettytable.Row{"no containers", "", "", "", "", "", "", ""})
+		tw.Render()
+		return nil
+	}
+	for _, c := range changes {
+		name := TruncateName(c.Name, noTrunc, 25)
+		switch {
+		case c.Removed():
+			s := c.Before
+			tw.AppendRow(prettytable.Row{name, text.Colors{text.FgHiRed}.Sprint("removed"), s.Status,
+				fmt.Sprintf("%.1f", s.CPUPercent), HumanizeBytes(s.MemUsage), "", "", TruncateName(s.Image, noTrunc, 40)})
+		case c.Added():
+			s := c.After
+			tw.AppendRow(prettytable.Row{name, text.Colors{text.FgGreen}.Sprint("added"), s.Status,
+				fmt.Sprintf("%.1f", s.CPUPercent), HumanizeBytes(s.MemUsage), "", "", TruncateName(s.Image, noTrunc, 40)})
+		default:
+			tw.AppendRow(changedRow(name, c, noTrunc))
+		}
+	}
+	tw.Render()
+	return nil
+}
+
+// changedRow compares a container present in both snapshots.
+func changedRow(name string, c store.Change, noTrunc bool) prettytable.Row {
+	x, y := c.Before, c.After
+	change := ""
+	netIO, blkIO := "", ""
+	if c.Recreated() {
+		// Counters restarted with the new container; totals since then are
+		// all there is.
+		change = text.Colors{text.FgYellow}.Sprint("recreated")
+		netIO, blkIO = printableIO(y.NetRx, y.NetTx), printableIO(y.BlockRead, y.BlockWrite)
+	} else {
+		netIO = printableIO(counterDelta(x.NetRx, y.NetRx), counterDelta(x.NetTx, y.NetTx))
+		blkIO = printableIO(counterDelta(x.BlockRead, y.BlockRead), counterDelta(x.BlockWrite, y.BlockWrite))
+	}
+	status := y.Status
+	if x.State != y.State {
+		status = fmt.Sprintf("%s → %s", x.State, y.Status)
+	}
+	image := TruncateName(y.Image, noTrunc, 40)
+	if x.Image != y.Image || (x.ImageID != "" && y.ImageID != "" && x.ImageID != y.ImageID) {
+		image = text.Colors{text.FgYellow}.Sprint(TruncateName(fmt.Sprintf("%s → %s", imageLabel(*x), imageLabel(*y)), noTrunc, 40))
+	}
+	cpu := fmt.Sprintf("%.1f → %.1f (%s)", x.CPUPercent, y.CPUPercent, signedDelta(y.CPUPercent-x.CPUPercent, "%.1f"))
+	mem := fmt.Sprintf("%s → %s (%s)", HumanizeBytes(x.MemUsage), HumanizeBytes(y.MemUsage), signedBytes(int64(y.MemUsage)-int64(x.MemUsage)))
+	return prettytable.Row{name, change, status, cpu, mem, netIO, blkIO, image}
+}
+
+// imageLabel names an image by reference, or by short ID when the
+// reference stayed the same but the image behind it changed.
+func imageLabel(s dkr.ContainerSnapshot) string {
+	if s.ImageDigest != "" {
+		return s.Image + "@" + TruncateID(trimDigest(s.ImageDigest), false)
+	}
+	return s.Image + "@" + TruncateID(trimDigest(s.ImageID), false)
+}
+
+func trimDigest(d string) string {
+	if len(d) > 7 && d[:7] == "sha256:" {
+		return d[7:]
+	}
+	return d
+}
+
+// counterDelta is how much a cumulative counter grew; 0 if it went backwards.
+func counterDelta(before, after uint64) uint64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
+
+func signedDelta(d float64, format string) string {
+	if d >= 0 {
+		return "+" + fmt.Sprintf(format, d)
+	}
+	return "−" + fmt.Sprintf(format, -d)
+}
+
+func signedBytes(d int64) string {
+	if d >= 0 {
+		return "+" + HumanizeBytes(uint64(d))
+	}
+	return "−" + HumanizeBytes(uint64(-d))
+}
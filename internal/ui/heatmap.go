@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// HeatMetric selects what shades the cells of the heatmap.
+type HeatMetric string
+
+const (
+	HeatCPU HeatMetric = "cpu"
+	HeatMem HeatMetric = "mem"
+)
+
+// heatCellWidth is the width of one heatmap cell, including the gap after it.
+const heatCellWidth = 12
+
+// HeatmapColumns is how many cells fit on a line of w.
+func HeatmapColumns(w io.Writer) int {
+	width := detectTerminalWidth(w)
+	if width <= 0 {
+		width = 120
+	}
+	return max(width/heatCellWidth, 1)
+}
+
+// SortHeatmap orders snaps by name, so each container keeps its place in the
+// grid from one refresh to the next.
+func SortHeatmap(snaps []dkr.ContainerSnapshot) {
+	sort.SliceStable(snaps, func(i, j int) bool {
+		return strings.ToLower(snaps[i].Name) < strings.ToLower(snaps[j].Name)
+	})
+}
+
+// RenderHeatmap draws one colored cell per container, HeatmapColumns to a
+// line, shaded by metric: green below 50%, yellow below 80%, red above,
+// grey when idle and blank without stats. CPU follows opts.CPUScale. When
+// selected is a valid index that cell is highlighted and the container's
+// card is printed below the grid.
+func RenderHeatmap(snaps []dkr.ContainerSnapshot, metric HeatMetric, selected int, opts RenderOptions, w io.Writer) {
+	by := "CPU"
+	if metric == HeatMem {
+		by = "memory"
+	}
+	fmt.Fprintln(w, text.Colors{text.Bold, text.FgHiWhite}.Sprintf("whale — heatmap by %s — %d containers — %s", by, len(snaps), titleTime(time.Now())))
+	cols := HeatmapColumns(w)
+	for i, s := range snaps {
+		if i > 0 && i%cols == 0 {
+			fmt.Fprintln(w)
+		}
+		cell := " " + text.Pad(heatLabel(s.Name, heatCellWidth-3), heatCellWidth-2, ' ')
+		colors := heatColors(s, metric, opts)
+		if i == selected {
+			colors = append(colors, text.Bold, text.ReverseVideo)
+		}
+		fmt.Fprint(w, colors.Sprint(cell), " ")
+	}
+	fmt.Fprintln(w)
+	legend := []string{
+		text.Colors{text.BgHiBlack, text.FgWhite}.Sprint(" idle "),
+		text.Colors{text.BgGreen, text.FgBlack}.Sprint(" <50% "),
+		text.Colors{text.BgYellow, text.FgBlack}.Sprint(" <80% "),
+		text.Colors{text.BgRed, text.FgWhite}.Sprint(" ≥80% "),
+		text.Colors{text.Faint}.Sprint(" no stats "),
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Join(legend, " "))
+	if selected >= 0 && selected < len(snaps) {
+		renderCards(snaps[selected:selected+1], opts, w)
+	}
+}
+
+// heatLabel shortens name to n characters from the left: in a fleet the
+// distinguishing part is usually the end (compose's "-web-3", a task ID).
+func heatLabel(name string, n int) string {
+	r := []rune(name)
+	if len(r) <= n {
+		return name
+	}
+	return "…" + string(r[len(r)-n+1:])
+}
+
+// heatColors picks the cell colors for s.
+func heatColors(s dkr.ContainerSnapshot, metric HeatMetric, opts RenderOptions) text.Colors {
+	if s.State != "running" || s.StatsUnavailable || strings.EqualFold(s.Status, "ERROR") {
+		return text.Colors{text.Faint}
+	}
+	pct := scaledCPU(s, opts)
+	if metric == HeatMem {
+		pct = s.MemPercent
+	}
+	switch {
+	case pct >= 80:
+		return text.Colors{text.BgRed, text.FgWhite}
+	case pct >= 50:
+		return text.Colors{text.BgYellow, text.FgBlack}
+	case pct >= 1:
+		return text.Colors{text.BgGreen, text.FgBlack}
+	default:
+		return text.Colors{text.BgHiBlack, text.FgWhite}
+	}
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// nameColors are cycled per container so interleaved log lines stay readable.
+var nameColors = []text.Color{text.FgCyan, text.FgMagenta, text.FgYellow, text.FgGreen, text.FgBlue, text.FgHiCyan, text.FgHiMagenta, text.FgHiYellow}
+
+// colorForName picks a stable color for a container name.
+func colorForName(name string) text.Colors {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return text.Colors{nameColors[int(h.Sum32())%len(nameColors)]}
+}
+
+// RenderLogMatches prints one line per match: container name, timestamp, text.
+func RenderLogMatches(w io.Writer, matches []dkr.LogMatch) {
+	if w == nil {
+		w = os.Stdout
+	}
+	width := 0
+	for _, m := range matches {
+		if len(m.Container) > width {
+			width = len(m.Container)
+		}
+	}
+	for _, m := range matches {
+		name := colorForName(m.Container).Sprint(fmt.Sprintf("%-*s", width, m.Container))
+		ts := text.Colors{text.Faint}.Sprint(logTime(m.Time))
+		fmt.Fprintf(w, "%s  %s  %s\n", name, ts, m.Line)
+	}
+}
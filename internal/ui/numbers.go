@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NumberFormat is how tables and cards write byte sizes, percentages and
+// counts. JSON output always uses plain numbers.
+type NumberFormat struct {
+	// Thousands separates groups of three digits; empty means no grouping.
+	Thousands string
+	// Decimal is the decimal mark.
+	Decimal string
+}
+
+// numberFormat is set once at startup by SetNumberFormat.
+var numberFormat = NumberFormat{Decimal: "."}
+
+// SetNumberFormat changes how numbers are written from now on. The default
+// is 1234567.8, as before.
+func SetNumberFormat(f NumberFormat) {
+	if f.Decimal == "" {
+		f.Decimal = "."
+	}
+	numberFormat = f
+}
+
+// LocaleNumberFormat returns the grouped number format of the locale named
+// by LC_ALL, LC_NUMERIC or LANG (the first one set), e.g. 1.234.567,8 for
+// de_DE.UTF-8. Locales it doesn't know, and C/POSIX, get 1,234,567.8.
+func LocaleNumberFormat(getenv func(string) string) NumberFormat {
+	locale := ""
+	for _, v := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if locale = getenv(v); locale != "" {
+			break
+		}
+	}
+	// de_CH.UTF-8@euro -> de, CH
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	lang, region, _ := strings.Cut(strings.ToLower(locale), "_")
+	switch {
+	case region == "ch" || region == "li":
+		return NumberFormat{Thousands: "'", Decimal: "."}
+	case lang == "de", lang == "nl", lang == "es", lang == "it", lang == "pt", lang == "da",
+		lang == "id", lang == "tr", lang == "el", lang == "ro", lang == "hr", lang == "sl":
+		return NumberFormat{Thousands: ".", Decimal: ","}
+	case lang == "fr", lang == "ru", lang == "uk", lang == "pl", lang == "cs", lang == "sk",
+		lang == "sv", lang == "fi", lang == "nb", lang == "nn", lang == "no", lang == "hu", lang == "bg":
+		// A no-break space, so a number never wraps inside a cell.
+		return NumberFormat{Thousands: "\u00a0", Decimal: ","}
+	}
+	return NumberFormat{Thousands: ",", Decimal: "."}
+}
+
+// formatInt writes n with thousands grouping, if enabled.
+func formatInt(n uint64) string {
+	return group(strconv.FormatUint(n, 10))
+}
+
+// formatFloat writes f with prec decimals, the decimal mark and grouping of
+// the number format.
+func formatFloat(f float64, prec int) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, ok := strings.Cut(s, ".")
+	whole = sign + group(whole)
+	if !ok {
+		return whole
+	}
+	return whole + numberFormat.Decimal + frac
+}
+
+// group inserts the thousands separator into a string of digits.
+func group(digits string) string {
+	sep := numberFormat.Thousands
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
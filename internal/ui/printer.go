@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"sort"
 	"strings"
@@ -22,6 +23,10 @@ type OutputFormat string
 const (
 	FormatTable OutputFormat = "table"
 	FormatJSON  OutputFormat = "json"
+	// FormatCSV is one row per container with raw numbers, for spreadsheets.
+	FormatCSV OutputFormat = "csv"
+	// FormatTemplate is "template:<text/template>", executed per container.
+	FormatTemplate OutputFormat = "template"
 )
 
 // SortKey controls ordering of snapshots.
@@ -31,8 +36,42 @@ const (
 	SortCPU  SortKey = "cpu"
 	SortMem  SortKey = "mem"
 	SortName SortKey = "name"
+	// SortCPUTime ranks by CPU time used since start.
+	SortCPUTime SortKey = "cpu-time"
+	// Live rates, meaningful in watch mode.
+	SortNetRate   SortKey = "net-rate"
+	SortBlockRate SortKey = "disk-rate"
 )
 
+// sortHeaders maps the sortable container table headers to their keys.
+var sortHeaders = map[string]SortKey{
+	"NAME":      SortName,
+	"CPU %":     SortCPU,
+	"MEM":       SortMem,
+	"CPU TIME":  SortCPUTime,
+	"NET I/O":   SortNetRate,
+	"BLOCK I/O": SortBlockRate,
+}
+
+// HeaderSortKey returns the sort key of a container table column header as
+// rendered, with or without its sort indicator.
+func HeaderSortKey(header string) (SortKey, bool) {
+	k, ok := sortHeaders[strings.TrimSpace(strings.TrimRight(header, "▲▼"))]
+	return k, ok
+}
+
+// sortMark appends the sort indicator to label when key is the sort in
+// effect: ▲ for names (ascending), ▼ for the metrics (descending).
+func sortMark(label string, key, sorted SortKey) string {
+	switch {
+	case key != sorted:
+		return label
+	case key == SortName:
+		return label + " ▲"
+	}
+	return label + " ▼"
+}
+
 // NetGroup represents a network name and its member containers.
 type NetGroup struct {
 	Network    string
@@ -49,6 +88,16 @@ func SortSnapshots(snaps []dkr.ContainerSnapshot, key SortKey) {
 		sort.Slice(snaps, func(i, j int) bool {
 			return strings.ToLower(snaps[i].Name) < strings.ToLower(snaps[j].Name)
 		})
+	case SortCPUTime:
+		sort.Slice(snaps, func(i, j int) bool { return snaps[i].CPUTime > snaps[j].CPUTime })
+	case SortNetRate:
+		sort.Slice(snaps, func(i, j int) bool {
+			return snaps[i].NetRxRate+snaps[i].NetTxRate > snaps[j].NetRxRate+snaps[j].NetTxRate
+		})
+	case SortBlockRate:
+		sort.Slice(snaps, func(i, j int) bool {
+			return snaps[i].BlockReadRate+snaps[i].BlockWriteRate > snaps[j].BlockReadRate+snaps[j].BlockWriteRate
+		})
 	case SortCPU:
 		fallthrough
 	default:
@@ -56,6 +105,72 @@ func SortSnapshots(snaps []dkr.ContainerSnapshot, key SortKey) {
 	}
 }
 
+// pidsCell shows a container's process count, as "37 / 512" when it has a
+// pids limit: yellow from 75% of the limit (or while the count keeps
+// growing, marked ↑), red from 90%.
+func pidsCell(s dkr.ContainerSnapshot) string {
+	if s.PIDs <= 0 {
+		return "—"
+	}
+	cell := formatInt(uint64(s.PIDs))
+	if s.PIDsLimit > 0 {
+		cell = formatInt(uint64(s.PIDs)) + " / " + formatInt(s.PIDsLimit)
+	}
+	if s.PIDsGrowing {
+		cell += "↑"
+	}
+	switch used := float64(s.PIDs) / float64(max(s.PIDsLimit, 1)); {
+	case s.PIDsLimit > 0 && used >= 0.9:
+		return text.Colors{text.FgHiRed}.Sprint(cell)
+	case s.PIDsLimit > 0 && used >= 0.75, s.PIDsGrowing:
+		return text.Colors{text.FgYellow}.Sprint(cell)
+	}
+	return cell
+}
+
+// fdsCell shows "open / limit" for the process closest to its open-files
+// limit, yellow from 75% and red from 90%.
+func fdsCell(s dkr.ContainerSnapshot) string {
+	if s.FDs <= 0 {
+		return "—"
+	}
+	if s.FDLimit == 0 {
+		return formatInt(uint64(s.FDs))
+	}
+	cell := formatInt(uint64(s.FDs)) + " / " + formatInt(s.FDLimit)
+	switch used := float64(s.FDs) / float64(s.FDLimit); {
+	case used >= 0.9:
+		return text.Colors{text.FgHiRed}.Sprint(cell)
+	case used >= 0.75:
+		return text.Colors{text.FgYellow}.Sprint(cell)
+	}
+	return cell
+}
+
+// pidsColumnWidth fits the widest PIDS cell, at least the header.
+func pidsColumnWidth(snaps []dkr.ContainerSnapshot) int {
+	width := 5
+	for _, s := range snaps {
+		width = max(width, text.RuneWidthWithoutEscSequences(pidsCell(s)))
+	}
+	return width
+}
+
+// formatCPUTime shows CPU time at the precision that matters for its size:
+// "12.3s", "4m05s", "3h12m", "2d04h".
+func formatCPUTime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
 // TruncateID returns a 12-char Docker-like ID when noTrunc is false.
 func TruncateID(id string, noTrunc bool) string {
 	if noTrunc || len(id) <= 12 {
@@ -65,17 +180,31 @@ func TruncateID(id string, noTrunc bool) string {
 }
 
 // TruncateName trims long names unless noTrunc is set. Keeps table tidy.
+// max is in terminal columns, so wide (e.g. CJK) characters count twice and
+// multi-byte characters are never split.
 func TruncateName(name string, noTrunc bool, max int) string {
 	if max <= 0 {
 		max = 25
 	}
-	if noTrunc || len(name) <= max {
+	if noTrunc || text.RuneWidthWithoutEscSequences(name) <= max {
 		return name
 	}
-	if max <= 1 {
-		return name[:max]
+	room := max
+	if max > 1 {
+		room-- // for the ellipsis
 	}
-	return name[:max-1] + "…"
+	var b strings.Builder
+	w := 0
+	for _, r := range name {
+		if w += text.RuneWidth(r); w > room {
+			break
+		}
+		b.WriteRune(r)
+	}
+	if max > 1 {
+		b.WriteString("…")
+	}
+	return b.String()
 }
 
 // HumanizeBytes formats bytes using IEC units (KiB, MiB, GiB).
@@ -88,33 +217,315 @@ func HumanizeBytes(b uint64) string {
 	)
 	switch {
 	case b >= TiB:
-		return fmt.Sprintf("%.2fTiB", float64(b)/float64(TiB))
+		return formatFloat(float64(b)/float64(TiB), 2) + "TiB"
 	case b >= GiB:
-		return fmt.Sprintf("%.2fGiB", float64(b)/float64(GiB))
+		return formatFloat(float64(b)/float64(GiB), 2) + "GiB"
 	case b >= MiB:
-		return fmt.Sprintf("%.2fMiB", float64(b)/float64(MiB))
+		return formatFloat(float64(b)/float64(MiB), 2) + "MiB"
 	case b >= KiB:
-		return fmt.Sprintf("%.2fKiB", float64(b)/float64(KiB))
+		return formatFloat(float64(b)/float64(KiB), 2) + "KiB"
 	default:
-		return fmt.Sprintf("%dB", b)
+		return formatInt(b) + "B"
 	}
 }
 
-// Render renders to stdout using the requested format.
-func Render(snaps []dkr.ContainerSnapshot, format OutputFormat, noTrunc bool, w io.Writer) error {
-	switch format {
-	case FormatJSON:
-		return renderJSON(snaps, w)
-	case FormatTable:
+// RenderOptions controls optional parts of the container table.
+type RenderOptions struct {
+	// NoTrunc shows full IDs, names and other long values.
+	NoTrunc bool
+	// ShowCommand adds a COMMAND column, as in `docker ps`.
+	ShowCommand bool
+	// NoStats renders the container list without metric columns: name, ID,
+	// status, image and ports, plus any extra columns.
+	NoStats bool
+	// ShowImage adds an IMAGE column with the image reference and digest.
+	ShowImage bool
+	// ShowLogErrors adds an ERRORS column with recent matching log lines.
+	ShowLogErrors bool
+	// ShowLastLog adds a LAST LOG column with each container's latest log line.
+	ShowLastLog bool
+	// ShowRates shows NET I/O and BLOCK I/O as current per-second rates
+	// instead of totals.
+	ShowRates bool
+	// Layout picks table or card rendering; the zero value means LayoutAuto.
+	Layout Layout
+	// ColumnPriority lists table columns by header, most important first
+	// (e.g. NAME, CPU %, MEM). When set, the least important columns shrink
+	// first and are dropped entirely when the terminal is too narrow; unlisted
+	// columns rank below listed ones. Short names cpu, net and block work too.
+	ColumnPriority []string
+	// Page, when Total is set, says snaps is one page of a longer list and
+	// is shown in the title ("showing 21–40 of 212").
+	Page Page
+	// IOSince, when set, notes in the title that NET I/O and BLOCK I/O are
+	// totals since that time rather than since container start.
+	IOSince time.Time
+	// Host, when non-nil, is attached to every JSON row so output from
+	// several hosts can be merged. For a Docker Desktop VM the table title
+	// also notes the VM's size.
+	Host *dkr.HostInfo
+	// ImageMaxAge, when set, highlights containers whose image was built
+	// longer ago than this in the IMAGE column.
+	ImageMaxAge time.Duration
+	// ShowCPUTime adds a CPU TIME column with the CPU time each container
+	// has used since it started.
+	ShowCPUTime bool
+	// ShowFDs adds an FDS column with open file descriptors against the
+	// open-files limit.
+	ShowFDs bool
+	// ShowConns adds a CONNS column with established TCP connections.
+	ShowConns bool
+	// ShowMounts adds a MNTS column with the number of volumes and binds.
+	ShowMounts bool
+	// LabelPrefixes limits the labels included in JSON to keys starting with
+	// one of these prefixes; empty includes all labels.
+	LabelPrefixes []string
+	// Selected is the ID of the container highlighted in watch mode, if any.
+	Selected string
+	// Stale, when set, is when snaps were collected: they are a cached
+	// list shown while a fresh one is collected.
+	Stale time.Time
+	// Sort is the order snaps are in, marked on its column header; empty
+	// marks none.
+	Sort SortKey
+	// CPUScale sets what a full CPU bar means; the zero value means
+	// CPUScalePercent.
+	CPUScale CPUScale
+}
+
+// CPUScale selects what 100% of the CPU bar (and its colors) stands for.
+// The CPU % text is always Docker's figure, where one busy core is 100%.
+type CPUScale string
+
+const (
+	// CPUScalePercent fills the bar at 100%, one core.
+	CPUScalePercent CPUScale = "percent"
+	// CPUScaleCores fills the bar when every CPU the container can run on
+	// is busy, so 400% on an 8-core host shows half full.
+	CPUScaleCores CPUScale = "cores"
+	// CPUScaleLimit fills the bar at the container's CPU limit (--cpus),
+	// falling back to its cores when it has none.
+	CPUScaleLimit CPUScale = "limit"
+)
+
+// scaledCPU returns s's CPU% relative to the capacity opts.CPUScale picks.
+func scaledCPU(s dkr.ContainerSnapshot, opts RenderOptions) float64 {
+	return s.CPUPercent / cpuCapacity(s, opts)
+}
+
+// cpuCapacity is the number of cores a full CPU bar stands for.
+func cpuCapacity(s dkr.ContainerSnapshot, opts RenderOptions) float64 {
+	switch opts.CPUScale {
+	case CPUScaleLimit:
+		if s.CPULimit > 0 {
+			return s.CPULimit
+		}
 		fallthrough
-	default:
-		renderTable(snaps, noTrunc, w)
-		return nil
+	case CPUScaleCores:
+		if s.OnlineCPUs > 0 {
+			return float64(s.OnlineCPUs)
+		}
+	}
+	return 1
+}
+
+// Render renders to stdout using the requested format.
+func Render(snaps []dkr.ContainerSnapshot, format OutputFormat, opts RenderOptions, w io.Writer) error {
+	r, err := NewRenderer(string(format))
+	if err != nil {
+		return err
 	}
+	return r.Render(w, snaps, opts)
+}
+
+// StatsFailures counts the listed containers whose stats could not be read
+// this time, whether shown as ERROR, without metrics or with stale values.
+func StatsFailures(snaps []dkr.ContainerSnapshot) int {
+	n := 0
+	for _, s := range snaps {
+		if !s.Gone && (s.Status == "ERROR" || s.StatsUnavailable || s.Stale) {
+			n++
+		}
+	}
+	return n
+}
+
+// column is an optional table column appended after the fixed ones.
+type column struct {
+	header   string
+	width    int // preferred content width
+	minWidth int // the width model may shrink it down to this
+	align    text.Align
+	cell     func(s dkr.ContainerSnapshot, width int) string
+}
+
+// extraColumns returns the optional columns enabled by opts, in display order.
+// Plugin-provided values (ContainerSnapshot.Extra) follow, one column per key.
+func extraColumns(snaps []dkr.ContainerSnapshot, opts RenderOptions) []column {
+	var cols []column
+	if opts.ShowCommand {
+		cols = append(cols, column{
+			header:   "COMMAND",
+			width:    30,
+			minWidth: 12,
+			cell: func(s dkr.ContainerSnapshot, width int) string {
+				return TruncateName(s.Command, opts.NoTrunc, width)
+			},
+		})
+	}
+	if opts.ShowImage {
+		cols = append(cols, column{
+			header:   "IMAGE",
+			width:    30,
+			minWidth: 12,
+			cell: func(s dkr.ContainerSnapshot, width int) string {
+				img := s.Image
+				if s.ImageDigest != "" {
+					d := strings.TrimPrefix(s.ImageDigest, "sha256:")
+					if !opts.NoTrunc && len(d) > 12 {
+						d = d[:12]
+					}
+					img += "@" + d
+				}
+				if s.ImageCreated == nil {
+					return TruncateName(img, opts.NoTrunc, width)
+				}
+				// Keep the age visible when the reference is truncated.
+				age := " (" + humanAge(*s.ImageCreated) + ")"
+				cell := TruncateName(img, opts.NoTrunc, width-len(age)) + age
+				if imageTooOld(s, opts.ImageMaxAge) {
+					return text.Colors{text.FgYellow}.Sprint(cell)
+				}
+				return cell
+			},
+		})
+	}
+	if opts.ShowCPUTime {
+		cols = append(cols, column{
+			header:   "CPU TIME",
+			width:    8,
+			minWidth: 8,
+			align:    text.AlignRight,
+			cell: func(s dkr.ContainerSnapshot, _ int) string {
+				if s.CPUTime <= 0 {
+					return "—"
+				}
+				return formatCPUTime(s.CPUTime)
+			},
+		})
+	}
+	if opts.ShowFDs {
+		cols = append(cols, column{
+			header:   "FDS",
+			width:    13,
+			minWidth: 5,
+			align:    text.AlignRight,
+			cell: func(s dkr.ContainerSnapshot, _ int) string {
+				return fdsCell(s)
+			},
+		})
+	}
+	if opts.ShowConns {
+		cols = append(cols, column{
+			header:   "CONNS",
+			width:    6, // "12,345" with --thousands
+			minWidth: 5,
+			align:    text.AlignRight,
+			cell: func(s dkr.ContainerSnapshot, _ int) string {
+				if s.Conns == nil {
+					return "—"
+				}
+				return formatInt(uint64(*s.Conns))
+			},
+		})
+	}
+	if opts.ShowMounts {
+		cols = append(cols, column{
+			header:   "MNTS",
+			width:    4,
+			minWidth: 4,
+			align:    text.AlignRight,
+			cell: func(s dkr.ContainerSnapshot, _ int) string {
+				if len(s.Mounts) == 0 {
+					return "—"
+				}
+				n := fmt.Sprintf("%d", len(s.Mounts))
+				for _, m := range s.Mounts {
+					if m.Warning != "" {
+						// See `whale mounts --audit` for which path.
+						return text.Colors{text.FgHiRed}.Sprint(n + "!")
+					}
+				}
+				return n
+			},
+		})
+	}
+	if opts.ShowLogErrors {
+		cols = append(cols, column{
+			header:   "ERRORS",
+			width:    6,
+			minWidth: 6,
+			align:    text.AlignRight,
+			cell: func(s dkr.ContainerSnapshot, _ int) string {
+				if s.LogErrors == nil {
+					return ""
+				}
+				if s.LogErrorsTruncated {
+					// Only the newest part of the window was read.
+					return text.Colors{text.FgHiRed}.Sprint("≥" + formatInt(uint64(*s.LogErrors)))
+				}
+				if *s.LogErrors == 0 {
+					return "—"
+				}
+				return text.Colors{text.FgHiRed}.Sprint(formatInt(uint64(*s.LogErrors)))
+			},
+		})
+	}
+	if opts.ShowLastLog {
+		cols = append(cols, column{
+			header:   "LAST LOG",
+			width:    40,
+			minWidth: 16,
+			cell: func(s dkr.ContainerSnapshot, width int) string {
+				return text.Colors{text.Faint}.Sprint(TruncateName(s.LastLog, opts.NoTrunc, width))
+			},
+		})
+	}
+	for _, key := range extraKeys(snaps) {
+		key := key
+		cols = append(cols, column{
+			header:   key,
+			width:    16,
+			minWidth: 8,
+			cell: func(s dkr.ContainerSnapshot, width int) string {
+				return TruncateName(s.Extra[key], opts.NoTrunc, width)
+			},
+		})
+	}
+	return cols
+}
+
+// extraKeys returns the sorted union of Extra keys across snaps.
+func extraKeys(snaps []dkr.ContainerSnapshot) []string {
+	seen := map[string]struct{}{}
+	for _, s := range snaps {
+		for k := range s.Extra {
+			seen[k] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // RenderNetworks prints containers grouped by network in a readable table.
-func RenderNetworks(groups map[string][]dkr.ContainerNetInfo, noTrunc bool, w io.Writer) error {
+// notes, keyed by network name, are shown dimmed under the network's name
+// (see dkr.NetworkParents); it may be nil. Swarm services are shown in cyan
+// (yellow while degraded).
+func RenderNetworks(groups map[string][]dkr.ContainerNetInfo, notes map[string]string, noTrunc bool, w io.Writer) error {
 	// Prepare a deterministic order of networks
 	networkNames := make([]string, 0, len(groups))
 	for n := range groups {
@@ -136,7 +547,7 @@ func RenderNetworks(groups map[string][]dkr.ContainerNetInfo, noTrunc bool, w io
 	if width > 0 {
 		tw.SetAllowedRowLength(width)
 	}
-	tw.SetTitle(fmt.Sprintf("whale — networks: %d — %s", len(networkNames), time.Now().Format(time.Kitchen)))
+	tw.SetTitle(fmt.Sprintf("whale — networks: %d — %s", len(networkNames), titleTime(time.Now())))
 	tw.AppendHeader(prettytable.Row{"NETWORK", "NAME", "ID", "STATUS"})
 	// Wider NAME when grouped view
 	nameMax := 40
@@ -152,7 +563,7 @@ func RenderNetworks(groups map[string][]dkr.ContainerNetInfo, noTrunc bool, w io
 		{Name: "NETWORK", WidthMax: 24, AutoMerge: true},
 		{Name: "NAME", WidthMax: nameMax},
 		{Name: "ID", WidthMax: 12},
-		{Name: "STATUS", WidthMax: 24},
+		{Name: "STATUS", WidthMax: 40},
 	})
 
 	if len(networkNames) == 0 {
@@ -164,10 +575,19 @@ func RenderNetworks(groups map[string][]dkr.ContainerNetInfo, noTrunc bool, w io
 	for _, netName := range networkNames {
 		containers := groups[netName]
 		coloredNet := text.Colors{text.FgCyan}.Sprint(netName)
+		if note := notes[netName]; note != "" {
+			coloredNet += "\n" + text.Colors{text.Faint}.Sprint(note)
+		}
 		for _, c := range containers {
 			name := TruncateName(c.Name, noTrunc, nameMax)
 			id := TruncateID(c.ID, noTrunc)
 			status := colorStatus(c.Status)
+			if c.Service {
+				status = text.Colors{text.FgCyan}.Sprint(c.Status)
+				if strings.Contains(c.Status, "degraded") {
+					status = text.Colors{text.FgYellow}.Sprint(c.Status)
+				}
+			}
 			tw.AppendRow(prettytable.Row{coloredNet, name, id, status})
 		}
 	}
@@ -175,45 +595,174 @@ func RenderNetworks(groups map[string][]dkr.ContainerNetInfo, noTrunc bool, w io
 	return nil
 }
 
-func renderJSON(snaps []dkr.ContainerSnapshot, w io.Writer) error {
-	// Convert to a machine-friendly structure with snake_case keys
-	type row struct {
-		Name       string  `json:"name"`
-		ID         string  `json:"id"`
-		Status     string  `json:"status"`
-		CPUPercent float64 `json:"cpu_percent"`
-		MemUsage   uint64  `json:"mem_usage"`
-		MemLimit   uint64  `json:"mem_limit"`
-		MemPercent float64 `json:"mem_percent"`
-		NetRx      uint64  `json:"net_rx"`
-		NetTx      uint64  `json:"net_tx"`
-		BlockRead  uint64  `json:"block_read"`
-		BlockWrite uint64  `json:"block_write"`
-		PIDs       int     `json:"pids"`
-	}
-	rows := make([]row, 0, len(snaps))
-	for _, s := range snaps {
-		rows = append(rows, row{
-			Name:       s.Name,
-			ID:         s.ID,
-			Status:     s.Status,
-			CPUPercent: round1(s.CPUPercent),
-			MemUsage:   s.MemUsage,
-			MemLimit:   s.MemLimit,
-			MemPercent: round1(s.MemPercent),
-			NetRx:      s.NetRx,
-			NetTx:      s.NetTx,
-			BlockRead:  s.BlockRead,
-			BlockWrite: s.BlockWrite,
-			PIDs:       s.PIDs,
+// RenderNetworkDetails renders several networks as RenderNetworkDetail does,
+// separated by blank lines; JSON is an array.
+func RenderNetworkDetails(list []dkr.NetworkDetail, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if list == nil {
+			list = []dkr.NetworkDetail{}
+		}
+		return enc.Encode(list)
+	}
+	for i, d := range list {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := RenderNetworkDetail(d, format, noTrunc, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderNetworkDetail prints one network's settings (driver, MTU, driver
+// options, subnets) followed by a table of its endpoints: container,
+// addresses, MAC and endpoint ID. MAC addresses shared by two endpoints are
+// shown in red.
+func RenderNetworkDetail(d dkr.NetworkDetail, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	label := text.Colors{text.Faint}
+	fmt.Fprintf(w, "%s %s  %s\n", label.Sprint("NETWORK"), text.Colors{text.FgCyan, text.Bold}.Sprint(d.Name), label.Sprint(TruncateID(d.ID, noTrunc)))
+	driver := fmt.Sprintf("%s (%s)", d.Driver, d.Scope)
+	if d.Internal {
+		driver += ", internal"
+	}
+	fmt.Fprintf(w, "%s  %s\n", label.Sprint("DRIVER "), driver)
+	if d.Parent != "" {
+		fmt.Fprintf(w, "%s  %s (%s mode)\n", label.Sprint("PARENT "), d.Parent, d.Mode)
+	}
+	mtu := d.MTU
+	if mtu == "" {
+		mtu = label.Sprint("not set (daemon default, usually 1500)")
+	}
+	fmt.Fprintf(w, "%s  %s\n", label.Sprint("MTU    "), mtu)
+	opts := make([]string, 0, len(d.Options))
+	for k := range d.Options {
+		opts = append(opts, k)
+	}
+	sort.Strings(opts)
+	for _, k := range opts {
+		fmt.Fprintf(w, "%s  %s=%s\n", label.Sprint("OPTION "), k, d.Options[k])
+	}
+	for _, sn := range d.Subnets {
+		subnet := sn.Subnet
+		if sn.Gateway != "" {
+			subnet += " via " + sn.Gateway
+		}
+		fmt.Fprintf(w, "%s  %s\n", label.Sprint("SUBNET "), subnet)
+	}
+
+	tw := prettytable.NewWriter()
+	if w == nil {
+		tw.SetOutputMirror(os.Stdout)
+	} else {
+		tw.SetOutputMirror(w)
+	}
+	styleN := prettytable.StyleRounded
+	styleN.Options.SeparateRows = true
+	styleN.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
+	tw.SetStyle(styleN)
+	if width := detectTerminalWidth(w); width > 0 {
+		tw.SetAllowedRowLength(width)
+	}
+	tw.SetTitle(fmt.Sprintf("whale — endpoints: %d — %s", len(d.Endpoints), titleTime(time.Now())))
+	tw.AppendHeader(prettytable.Row{"CONTAINER", "ID", "IPV4", "IPV6", "MAC", "ENDPOINT"})
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "CONTAINER", WidthMax: 30},
+		{Name: "IPV6", WidthMax: 30},
+	})
+	if len(d.Endpoints) == 0 {
+		tw.AppendFooter(prettytable.Row{"no endpoints", "", "", "", "", ""})
+		tw.Render()
+		return nil
+	}
+	for _, ep := range d.Endpoints {
+		mac := ep.MAC
+		if ep.DuplicateMAC {
+			mac = text.Colors{text.FgHiRed, text.Bold}.Sprint(mac + " dup")
+		}
+		tw.AppendRow(prettytable.Row{
+			TruncateName(ep.Container, noTrunc, 30),
+			TruncateID(ep.ContainerID, noTrunc),
+			dashIfEmpty(ep.IPv4),
+			dashIfEmpty(ep.IPv6),
+			dashIfEmpty(mac),
+			TruncateID(ep.EndpointID, noTrunc),
 		})
 	}
+	tw.Render()
+	return nil
+}
+
+// RenderReadiness prints the containers checked by `whale wait` that are not
+// ready, with the reason for each.
+func RenderReadiness(statuses []dkr.ReadyStatus, w io.Writer) error {
+	tw := prettytable.NewWriter()
+	tw.SetOutputMirror(w)
+	style := prettytable.StyleRounded
+	style.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
+	tw.SetStyle(style)
+	tw.AppendHeader(prettytable.Row{"NAME", "ID", "STATE", "HEALTH", "PROBLEM"})
+	for _, s := range statuses {
+		if s.Ready {
+			continue
+		}
+		health := s.Health
+		if health == "" {
+			health = "—"
+		}
+		tw.AppendRow(prettytable.Row{s.Name, TruncateID(s.ID, false), colorStatus(s.State), health, text.Colors{text.FgRed}.Sprint(s.Reason)})
+	}
+	tw.Render()
+	return nil
+}
+
+func renderJSON(snaps []dkr.ContainerSnapshot, opts RenderOptions, w io.Writer) error {
+	partial := StatsFailures(snaps) > 0
+	rows := make([]dkr.Row, 0, len(snaps))
+	for _, s := range snaps {
+		r := dkr.NewRow(s)
+		r.Labels = filterLabels(s.Labels, opts.LabelPrefixes)
+		r.ImageTooOld = imageTooOld(s, opts.ImageMaxAge)
+		r.CPUPercent = round1(r.CPUPercent)
+		r.MemPercent = round1(r.MemPercent)
+		r.CPUSeconds = round1(r.CPUSeconds)
+		r.Host = opts.Host
+		r.Partial = partial
+		rows = append(rows, r)
+	}
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	return enc.Encode(rows)
 }
 
-func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
+// filterLabels returns the labels whose keys start with one of prefixes, or
+// all of them when prefixes is empty.
+func filterLabels(labels map[string]string, prefixes []string) map[string]string {
+	if len(prefixes) == 0 || len(labels) == 0 {
+		return labels
+	}
+	out := make(map[string]string)
+	for k, v := range labels {
+		for _, p := range prefixes {
+			if strings.HasPrefix(k, p) {
+				out[k] = v
+				break
+			}
+		}
+	}
+	return out
+}
+
+func renderTable(snaps []dkr.ContainerSnapshot, opts RenderOptions, w io.Writer) {
+	noTrunc := opts.NoTrunc
+	extras := extraColumns(snaps, opts)
 	tw := prettytable.NewWriter()
 	if w == nil {
 		tw.SetOutputMirror(os.Stdout)
@@ -225,7 +774,7 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 	style.Options.SeparateRows = true
 	style.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
 	tw.SetStyle(style)
-	tw.SetTitle(fmt.Sprintf("whale — %d containers — %s", len(snaps), time.Now().Format(time.Kitchen)))
+	tw.SetTitle(listTitle(snaps, opts))
 	// Detect terminal width and hint the writer to wrap as needed
 	width := detectTerminalWidth(w)
 	if width > 0 {
@@ -245,19 +794,78 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 	cpuBarWidth := 10
 	memBarWidth := 10
 	percentDigits := 6 // e.g., "100.0"
-	percentColWidthCPU := percentDigits + 1 + boolToInt(cpuBarWidth > 0)*(cpuBarWidth+2)
+	// Room for the "σ " anomaly mark when any row carries one.
+	cpuMark := 0
+	for _, s := range snaps {
+		if s.CPUAnomaly {
+			cpuMark = 2
+			break
+		}
+	}
+	// Likewise for the time-to-limit estimate on leaking containers.
+	memMark := 0
+	for _, s := range snaps {
+		if s.MemFullIn > 0 {
+			memMark = memFullInWidth
+			break
+		}
+	}
+	percentColWidthCPU := cpuMark + percentDigits + 1 + boolToInt(cpuBarWidth > 0)*(cpuBarWidth+2)
 	// Merge MEM usage/limit and percent into a single MEM column width
-	memColWidth := 26 + 1 + percentDigits + boolToInt(memBarWidth > 0)*(memBarWidth+2)
+	memColWidth := memMark + 26 + 1 + percentDigits + boolToInt(memBarWidth > 0)*(memBarWidth+2)
+	pidsWidth := pidsColumnWidth(snaps)
 	netWidth := 22
 	blkWidth := 22
-	// total width model (borders + paddings + content widths) for 8 columns
+	// Columns the width model may drop entirely (only with ColumnPriority).
+	dropped := map[string]bool{}
+	// label is a header as shown, with the sort indicator if it is sorted by.
+	label := func(header string) string {
+		if k, ok := sortHeaders[header]; ok {
+			return sortMark(header, k, opts.Sort)
+		}
+		return header
+	}
+	// total width model (borders + paddings + content widths) for the
+	// visible fixed columns plus extras
 	calcTotal := func() int {
-		cols := 8
-		sep := cols + 1
-		pad := cols * 2
-		return sep + pad + nameMax + idMax + 24 + percentColWidthCPU + memColWidth + netWidth + blkWidth + 5
+		cols, content := 0, 0
+		add := func(header string, w int) {
+			if !dropped[header] {
+				cols++
+				content += max(w, text.RuneWidthWithoutEscSequences(label(header)))
+			}
+		}
+		add("NAME", nameMax)
+		add("ID", idMax)
+		add("STATUS", 24)
+		add("CPU %", percentColWidthCPU)
+		add("MEM", memColWidth)
+		add("NET I/O", netWidth)
+		add("BLOCK I/O", blkWidth)
+		add("PIDS", pidsWidth)
+		for _, c := range extras {
+			add(c.header, c.width)
+		}
+		return (cols + 1) /*separators*/ + cols*2 /*padding*/ + content
 	}
-	// Adjust to fit terminal width by shrinking bars, then NAME, then NET/BLOCK, then MEM USAGE.
+	// Columns that give up width after the bars, in order. By default NAME,
+	// then NET/BLOCK, then MEM USAGE, with extras last (widest first); with
+	// ColumnPriority the least important column shrinks first.
+	shrinkers := []shrinker{
+		{"NAME", &nameMax, 12},
+		{"NET I/O", &netWidth, 16},
+		{"BLOCK I/O", &blkWidth, 16},
+		{"MEM", &memColWidth, 20},
+	}
+	if len(opts.ColumnPriority) > 0 {
+		for i := range extras {
+			shrinkers = append(shrinkers, shrinker{extras[i].header, &extras[i].width, extras[i].minWidth})
+		}
+		sort.SliceStable(shrinkers, func(i, j int) bool {
+			return columnRank(opts.ColumnPriority, shrinkers[i].header) > columnRank(opts.ColumnPriority, shrinkers[j].header)
+		})
+	}
+	// Adjust to fit terminal width by shrinking bars, then the columns above.
 	// Coarse pass: shrink bars based on width tiers
 	if width <= 80 {
 		cpuBarWidth, memBarWidth = 2, 2
@@ -266,8 +874,8 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 	} else if width <= 120 {
 		cpuBarWidth, memBarWidth = 8, 8
 	}
-	percentColWidthCPU = percentDigits + 1 + boolToInt(cpuBarWidth > 0)*(cpuBarWidth+2)
-	memColWidth = 26 + 1 + percentDigits + boolToInt(memBarWidth > 0)*(memBarWidth+2)
+	percentColWidthCPU = cpuMark + percentDigits + 1 + boolToInt(cpuBarWidth > 0)*(cpuBarWidth+2)
+	memColWidth = memMark + 26 + 1 + percentDigits + boolToInt(memBarWidth > 0)*(memBarWidth+2)
 	for total := calcTotal(); total > width; total = calcTotal() {
 		switch {
 		case cpuBarWidth > 0 || memBarWidth > 0:
@@ -277,16 +885,10 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 			if memBarWidth > 0 {
 				memBarWidth--
 			}
-			percentColWidthCPU = percentDigits + 1 + boolToInt(cpuBarWidth > 0)*(cpuBarWidth+2)
-			memColWidth = 26 + 1 + percentDigits + boolToInt(memBarWidth > 0)*(memBarWidth+2)
-		case nameMax > 12:
-			nameMax--
-		case netWidth > 16:
-			netWidth--
-		case blkWidth > 16:
-			blkWidth--
-		case memColWidth > 20:
-			memColWidth--
+			percentColWidthCPU = cpuMark + percentDigits + 1 + boolToInt(cpuBarWidth > 0)*(cpuBarWidth+2)
+			memColWidth = memMark + 26 + 1 + percentDigits + boolToInt(memBarWidth > 0)*(memBarWidth+2)
+		case shrinkNext(shrinkers):
+		case shrinkWidest(extras):
 		default:
 			// nothing else to shrink
 			break
@@ -296,8 +898,27 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 			break
 		}
 	}
+	allHeaders := []string{"NAME", "ID", "STATUS", "CPU %", "MEM", "NET I/O", "BLOCK I/O", "PIDS"}
+	for _, c := range extras {
+		allHeaders = append(allHeaders, c.header)
+	}
+	if len(opts.ColumnPriority) > 0 {
+		// Still too wide at minimum widths: drop whole columns, least
+		// important first. NAME always stays.
+		order := append([]string(nil), allHeaders[1:]...)
+		sort.SliceStable(order, func(i, j int) bool {
+			return columnRank(opts.ColumnPriority, order[i]) > columnRank(opts.ColumnPriority, order[j])
+		})
+		for _, h := range order {
+			if calcTotal() <= width {
+				break
+			}
+			dropped[h] = true
+			slog.Debug("column dropped to fit the terminal", "column", h, "width", width)
+		}
+	}
 	// Recompute NAME width as the remainder to ensure total fits the terminal
-	remainder := width - (8 + 1) /*separators*/ - (8 * 2) /*padding*/ - idMax - 24 - percentColWidthCPU - memColWidth - netWidth - blkWidth - 5
+	remainder := width - (calcTotal() - nameMax)
 	if remainder < 12 {
 		remainder = 12
 	}
@@ -306,7 +927,7 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 	}
 	nameMax = remainder
 
-	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+	configs := []prettytable.ColumnConfig{
 		{Name: "NAME", WidthMax: nameMax},
 		{Name: "ID", WidthMax: idMax},
 		{Name: "STATUS", WidthMax: 24},
@@ -314,11 +935,45 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 		{Name: "MEM", WidthMax: memColWidth},
 		{Name: "NET I/O", WidthMax: netWidth},
 		{Name: "BLOCK I/O", WidthMax: blkWidth},
-		{Name: "PIDS", Align: text.AlignRight, WidthMax: 5},
-	})
-	tw.AppendHeader(prettytable.Row{"NAME", "ID", "STATUS", "CPU %", "MEM", "NET I/O", "BLOCK I/O", "PIDS"})
+		{Name: "PIDS", Align: text.AlignRight, WidthMax: pidsWidth},
+	}
+	for _, c := range extras {
+		configs = append(configs, prettytable.ColumnConfig{Name: c.header, Align: c.align, WidthMax: c.width})
+	}
+	// visible filters a full row (one cell per allHeaders entry) down to the
+	// columns that were not dropped.
+	visible := func(row prettytable.Row) prettytable.Row {
+		if len(dropped) == 0 {
+			return row
+		}
+		out := row[:0:0]
+		for i, v := range row {
+			if !dropped[allHeaders[i]] {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+	var header prettytable.Row
+	shown := configs[:0:0]
+	for i, c := range configs {
+		if !dropped[c.Name] {
+			// Column configs are matched to the header by name.
+			c.Name = label(allHeaders[i])
+			c.WidthMax = max(c.WidthMax, text.RuneWidthWithoutEscSequences(c.Name))
+			shown = append(shown, c)
+			header = append(header, c.Name)
+		}
+	}
+	tw.SetColumnConfigs(shown)
+	tw.AppendHeader(header)
 	if len(snaps) == 0 {
-		tw.AppendFooter(prettytable.Row{"no containers", "", "", "", "", "", "", "", ""})
+		footer := make(prettytable.Row, len(header))
+		footer[0] = "no containers"
+		for i := 1; i < len(footer); i++ {
+			footer[i] = ""
+		}
+		tw.AppendFooter(footer)
 		tw.Render()
 		return
 	}
@@ -333,20 +988,24 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 		cpu := dashIfZeroPercent(s.CPUPercent)
 		memUsage := "—"
 		memLimit := "—"
-		if s.MemLimit > 0 {
+		if s.MemUsage > 0 {
 			memUsage = HumanizeBytes(s.MemUsage)
+		}
+		if s.MemLimit > 0 {
 			memLimit = HumanizeBytes(s.MemLimit)
 		}
 		memPct := dashIfZeroPercent(s.MemPercent)
 		netIO := printableIO(s.NetRx, s.NetTx)
 		blkIO := printableIO(s.BlockRead, s.BlockWrite)
-		pids := "—"
-		if s.PIDs > 0 {
-			pids = fmt.Sprintf("%d", s.PIDs)
+		if opts.ShowRates {
+			netIO = printableRate(s.NetRxRate, s.NetTxRate)
+			blkIO = printableRate(s.BlockReadRate, s.BlockWriteRate)
 		}
+		netIO = hostNetCell(s, netIO)
+		pids := pidsCell(s)
 
 		// If stats couldn't be read, show blanks for numeric fields.
-		if strings.EqualFold(s.Status, "ERROR") {
+		if strings.EqualFold(s.Status, "ERROR") || s.StatsUnavailable {
 			cpu = ""
 			memUsage, memLimit, memPct = "", "", ""
 			netIO, blkIO = "", ""
@@ -354,16 +1013,40 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 		}
 
 		// Color coding
-		status := colorStatus(s.Status)
-		cpu = formatPercent(cpu, s.CPUPercent, cpuBarWidth)
-		memPct = formatPercent(memPct, s.MemPercent, memBarWidth)
+		name = styleName(s, name)
+		if opts.Selected != "" && s.ID == opts.Selected {
+			name = text.Colors{text.ReverseVideo}.Sprint(name)
+		}
+		status := statusCell(s)
+		if !s.Stale {
+			cpu = formatPercent(cpu, scaledCPU(s, opts), cpuBarWidth)
+			memPct = formatPercent(memPct, s.MemPercent, memBarWidth)
+		}
+		cpu = anomalyMark(cpu, s.CPUAnomaly)
 
 		// Build MEM combined cell: "usage / limit  <percent and bar>"
 		memCombined := fmt.Sprintf("%s / %s", memUsage, memLimit)
 		if memPct != "" {
 			memCombined = fmt.Sprintf("%s  %s", memCombined, memPct)
 		}
-		tw.AppendRow(prettytable.Row{
+		memCombined = anomalyMark(memCombined, s.MemAnomaly)
+		if !s.Stale {
+			memCombined = memFullInMark(memCombined, s.MemFullIn)
+		}
+		if s.Stale {
+			// Last known values: dim them and skip the colored bars.
+			dim := text.Colors{text.Faint}
+			cpu, memCombined = dim.Sprint(cpu), dim.Sprint(memCombined)
+			netIO, blkIO, pids = dim.Sprint(netIO), dim.Sprint(blkIO), dim.Sprint(pids)
+		}
+		if s.State == "paused" {
+			// No stats are read for paused containers; say so instead of
+			// showing dashes that look like a failed read.
+			dim := text.Colors{text.Faint}
+			cpu, memCombined = dim.Sprint("paused"), dim.Sprint("—")
+			netIO, blkIO, pids = dim.Sprint("—"), dim.Sprint("—"), dim.Sprint("—")
+		}
+		row := prettytable.Row{
 			name,
 			id,
 			status,
@@ -372,12 +1055,183 @@ func renderTable(snaps []dkr.ContainerSnapshot, noTrunc bool, w io.Writer) {
 			netIO,
 			blkIO,
 			pids,
-		})
+		}
+		for _, c := range extras {
+			row = append(row, c.cell(s, c.width))
+		}
+		tw.AppendRow(visible(row))
 	}
 	tw.Render()
 }
 
+// renderListTable is the --no-stats table: `docker ps` data only, so it
+// needs no per-container daemon calls.
+func renderListTable(snaps []dkr.ContainerSnapshot, opts RenderOptions, w io.Writer) {
+	// IMAGE is a fixed column here, so drop the optional one.
+	opts.ShowImage = false
+	extras := extraColumns(snaps, opts)
+	tw := prettytable.NewWriter()
+	tw.SetOutputMirror(w)
+	style := prettytable.StyleRounded
+	style.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
+	tw.SetStyle(style)
+	tw.SetTitle(fmt.Sprintf("whale — %d containers — %s", len(snaps), titleTime(time.Now())))
+	if width := detectTerminalWidth(w); width > 0 {
+		tw.SetAllowedRowLength(width)
+	}
+	configs := []prettytable.ColumnConfig{
+		{Name: "NAME", WidthMax: 40},
+		{Name: "ID", WidthMax: 64},
+		{Name: "STATUS", WidthMax: 28},
+		{Name: "IMAGE", WidthMax: 40},
+		{Name: "PORTS", WidthMax: 40},
+	}
+	header := prettytable.Row{"NAME", "ID", "STATUS", "IMAGE", "PORTS"}
+	for _, c := range extras {
+		configs = append(configs, prettytable.ColumnConfig{Name: c.header, Align: c.align, WidthMax: c.width})
+		header = append(header, c.header)
+	}
+	tw.SetColumnConfigs(configs)
+	tw.AppendHeader(header)
+	for _, s := range snaps {
+		ports := strings.Join(s.Ports, ", ")
+		if ports == "" {
+			ports = "—"
+		}
+		row := prettytable.Row{
+			TruncateName(s.Name, opts.NoTrunc, 40),
+			TruncateID(s.ID, opts.NoTrunc),
+			colorStatus(s.Status),
+			TruncateName(s.Image, opts.NoTrunc, 40),
+			ports,
+		}
+		for _, c := range extras {
+			row = append(row, c.cell(s, c.width))
+		}
+		tw.AppendRow(row)
+	}
+	if len(snaps) == 0 {
+		footer := make(prettytable.Row, len(header))
+		footer[0] = "no containers"
+		tw.AppendFooter(footer)
+	}
+	tw.Render()
+}
+
+// Page locates the rendered rows within the full container list.
+type Page struct {
+	First int // 0-based index of the first rendered row
+	Total int // number of containers before paging
+}
+
+// listTitle is the title line of the container views.
+func listTitle(snaps []dkr.ContainerSnapshot, opts RenderOptions) string {
+	at := titleTime(time.Now())
+	if !opts.Stale.IsZero() {
+		at = "cached " + eventTime(opts.Stale) + ", refreshing…"
+	}
+	title := fmt.Sprintf("whale — %d containers — %s", len(snaps), at)
+	if opts.Page.Total > 0 {
+		title = fmt.Sprintf("whale — showing %d–%d of %d containers — %s",
+			opts.Page.First+1, opts.Page.First+len(snaps), opts.Page.Total, at)
+	}
+	if !opts.IOSince.IsZero() {
+		since := titleTime(opts.IOSince)
+		if timeStyle == TimeRelative {
+			since = eventTime(opts.IOSince)
+		}
+		title += " — I/O since " + since
+	}
+	if h := opts.Host; h != nil && h.VM {
+		// Limits are relative to the VM, not the physical machine.
+		title += fmt.Sprintf(" — Docker Desktop VM: %d CPUs, %s", h.CPUs, HumanizeBytes(uint64(h.MemTotal)))
+	}
+	return title
+}
+
+// RowsPerScreen estimates how many containers fit on the terminal behind w
+// in the given layout, or 0 when w is not a terminal. Table rows take two
+// lines (row plus separator) under a six-line frame; cards take four.
+func RowsPerScreen(layout Layout, w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	rows := (height - 6) / 2
+	if useCards(layout, w) {
+		rows = (height - 2) / 4
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// hostNetCell labels the NET I/O cell of host-network containers: "host
+// netns" when there are no counters, or the host-wide values marked (host)
+// when --host-net-io filled them in.
+func hostNetCell(s dkr.ContainerSnapshot, cell string) string {
+	if !s.HostNetwork {
+		return cell
+	}
+	dim := text.Colors{text.Faint}
+	if cell == "—" {
+		return dim.Sprint("host netns")
+	}
+	return cell + dim.Sprint(" (host)")
+}
+
+// statusCell renders the STATUS value with its badges: restart loops,
+// missing or stale stats, zombies, and new or departed containers.
+func statusCell(s dkr.ContainerSnapshot) string {
+	status := colorStatus(s.Status)
+	if s.Flapping {
+		// Restart loops override the usual state color.
+		status = text.Colors{text.FgHiMagenta, text.Bold}.Sprintf("%s ⟳%d flapping", s.Status, s.RecentRestarts)
+	}
+	if s.StatsUnavailable {
+		status += text.Colors{text.Faint}.Sprint(" (no stats)")
+	}
+	if s.Zombies > 0 {
+		status += text.Colors{text.FgHiRed}.Sprintf(" Z:%d", s.Zombies)
+	}
+	if s.New {
+		status += text.Colors{text.FgHiGreen, text.Bold}.Sprint(" NEW")
+	}
+	if s.Gone {
+		status = text.Colors{text.Faint}.Sprint(s.Status)
+	}
+	if s.Stale {
+		status += text.Colors{text.Faint}.Sprint(" (stale)")
+	}
+	return status
+}
+
+// styleName highlights new containers and dims departed and paused ones.
+func styleName(s dkr.ContainerSnapshot, name string) string {
+	switch {
+	case s.Gone, s.State == "paused":
+		return text.Colors{text.Faint}.Sprint(name)
+	case s.New:
+		return text.Colors{text.FgHiGreen, text.Bold}.Sprint(name)
+	}
+	return name
+}
+
+// TerminalWidther is implemented by writers that buffer output bound for a
+// terminal, so rendering into them lays out for that terminal's width.
+type TerminalWidther interface {
+	TerminalWidth() int
+}
+
 func detectTerminalWidth(w io.Writer) int {
+	if tw, ok := w.(TerminalWidther); ok {
+		return tw.TerminalWidth()
+	}
 	// Try to get terminal width from the writer if it's a file (stdout typically)
 	if w == nil {
 		if term.IsTerminal(int(os.Stdout.Fd())) {
@@ -402,11 +1256,25 @@ func printableIO(rx, tx uint64) string {
 	return fmt.Sprintf("%s / %s", HumanizeBytes(rx), HumanizeBytes(tx))
 }
 
+func printableRate(rx, tx float64) string {
+	if rx < 1 && tx < 1 {
+		return "—"
+	}
+	return fmt.Sprintf("%s/s / %s/s", HumanizeBytes(uint64(rx)), HumanizeBytes(uint64(tx)))
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
 func dashIfZeroPercent(p float64) string {
 	if p == 0 {
 		return "—"
 	}
-	return fmt.Sprintf("%.1f", p)
+	return formatFloat(p, 1)
 }
 
 func round1(v float64) float64 {
@@ -449,6 +1317,30 @@ func colorPercentString(val string, pct float64) string {
 	return fmt.Sprintf("%s %s", colored, bar)
 }
 
+// anomalyMark prefixes a cell whose reading is far off its baseline.
+func anomalyMark(cell string, anomalous bool) string {
+	if !anomalous || cell == "" {
+		return cell
+	}
+	return text.Colors{text.FgHiMagenta, text.Bold}.Sprint("σ") + " " + cell
+}
+
+// memFullInWidth is the room memFullInMark needs, e.g. "  full 23h59m".
+const memFullInWidth = 13
+
+// memFullInMark appends the estimated time until memory reaches the limit:
+// red within an hour, yellow otherwise.
+func memFullInMark(cell string, d time.Duration) string {
+	if d <= 0 || cell == "" {
+		return cell
+	}
+	c := text.Colors{text.FgYellow}
+	if d < time.Hour {
+		c = text.Colors{text.FgHiRed, text.Bold}
+	}
+	return cell + "  " + c.Sprint("full "+dkr.ShortDuration(d))
+}
+
 // formatPercent applies color and optionally appends a sized bar depending on width.
 func formatPercent(val string, pct float64, barWidth int) string {
 	if val == "" || val == "—" {
@@ -479,6 +1371,63 @@ func formatPercent(val string, pct float64, barWidth int) string {
 	return fmt.Sprintf("%s %s", colored, bar)
 }
 
+// shrinker is a column the width model may narrow, down to min.
+type shrinker struct {
+	header string
+	width  *int
+	min    int
+}
+
+// shrinkNext narrows the first shrinker that is still above its minimum.
+func shrinkNext(cols []shrinker) bool {
+	for _, c := range cols {
+		if *c.width > c.min {
+			*c.width--
+			return true
+		}
+	}
+	return false
+}
+
+// columnRank is header's position in priority (most important first);
+// unlisted columns rank below all listed ones.
+func columnRank(priority []string, header string) int {
+	for i, p := range priority {
+		if alias, ok := columnAliases[strings.ToLower(p)]; ok {
+			p = alias
+		}
+		if strings.EqualFold(p, header) {
+			return i
+		}
+	}
+	return len(priority)
+}
+
+// columnAliases are shorthand names for headers that contain spaces or symbols.
+var columnAliases = map[string]string{
+	"cpu":      "CPU %",
+	"net":      "NET I/O",
+	"block":    "BLOCK I/O",
+	"disk":     "BLOCK I/O",
+	"last-log": "LAST LOG",
+}
+
+// shrinkWidest narrows the widest optional column that is still above its
+// minimum by one character. It reports whether anything changed.
+func shrinkWidest(cols []column) bool {
+	widest := -1
+	for i, c := range cols {
+		if c.width > c.minWidth && (widest < 0 || c.width > cols[widest].width) {
+			widest = i
+		}
+	}
+	if widest < 0 {
+		return false
+	}
+	cols[widest].width--
+	return true
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
@@ -513,6 +1462,501 @@ func ClearScreen(w io.Writer) {
 	_, _ = io.WriteString(w, "\x1b[2J\x1b[H")
 }
 
+// RenderProfile writes a short timing report for one collect/render cycle,
+// listing the slowest per-container stats calls first.
+func RenderProfile(w io.Writer, t dkr.CollectTimings, render time.Duration, slowest int) {
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "profile: list %s, stats %s (%d containers), render %s\n",
+		t.List.Round(time.Millisecond), t.Stats.Round(time.Millisecond), len(t.Containers), render.Round(time.Millisecond))
+	ct := append([]dkr.ContainerTiming(nil), t.Containers...)
+	sort.Slice(ct, func(i, j int) bool { return ct[i].Duration > ct[j].Duration })
+	if slowest > 0 && len(ct) > slowest {
+		ct = ct[:slowest]
+	}
+	for _, c := range ct {
+		line := fmt.Sprintf("  %8s  %s (%s)", c.Duration.Round(time.Millisecond), c.Name, TruncateID(c.ID, false))
+		if c.Err != nil {
+			line += " error: " + c.Err.Error()
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// RenderExposed renders every host-bound port with its binding address.
+// Database and admin ports bound on all interfaces are flagged in red;
+// host-network containers get a row of their own, since every port they
+// listen on is on the host.
+func RenderExposed(list []dkr.ExposedPort, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	tw := newScanTable(w)
+	risky := 0
+	for _, p := range list {
+		if p.Risky {
+			risky++
+		}
+	}
+	tw.SetTitle(fmt.Sprintf("whale — exposed ports: %d, %d risky — %s", len(list), risky, titleTime(time.Now())))
+	tw.AppendHeader(prettytable.Row{"CONTAINER", "ADDRESS", "HOST PORT", "CONTAINER PORT", "NOTE"})
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "CONTAINER", WidthMax: 30},
+		{Name: "HOST PORT", Align: text.AlignRight},
+		{Name: "CONTAINER PORT", Align: text.AlignRight},
+	})
+	if len(list) == 0 {
+		tw.AppendFooter(prettytable.Row{"no published ports", "", "", "", ""})
+		tw.Render()
+		return nil
+	}
+	for _, p := range list {
+		name := TruncateName(p.Container, noTrunc, 30)
+		if p.HostNetwork {
+			tw.AppendRow(prettytable.Row{name, text.Colors{text.FgYellow}.Sprint("host network"), "all", "all",
+				text.Colors{text.FgYellow}.Sprint("every listening port is on the host")})
+			continue
+		}
+		addr := p.HostIP
+		if addr == "" {
+			addr = "0.0.0.0"
+		}
+		if p.Public() {
+			addr = text.Colors{text.FgYellow}.Sprint(addr)
+		}
+		note := p.Service
+		if p.Risky {
+			note = text.Colors{text.FgHiRed, text.Bold}.Sprintf("%s open on all interfaces", p.Service)
+		}
+		tw.AppendRow(prettytable.Row{name, addr, p.HostPort, fmt.Sprintf("%d/%s", p.ContainerPort, p.Proto), note})
+	}
+	tw.Render()
+	return nil
+}
+
+// RenderMounts renders every mount per container: type, source,
+// destination and whether it is writable.
+func RenderMounts(list []dkr.ContainerMounts, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	tw := prettytable.NewWriter()
+	if w == nil {
+		tw.SetOutputMirror(os.Stdout)
+	} else {
+		tw.SetOutputMirror(w)
+	}
+	styleM := prettytable.StyleRounded
+	styleM.Options.SeparateRows = true
+	styleM.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
+	tw.SetStyle(styleM)
+	width := detectTerminalWidth(w)
+	if width > 0 {
+		tw.SetAllowedRowLength(width)
+	}
+	total := 0
+	for _, c := range list {
+		total += len(c.Mounts)
+	}
+	tw.SetTitle(fmt.Sprintf("whale — mounts: %d in %d containers — %s", total, len(list), titleTime(time.Now())))
+	// A SIZE column appears once volume sizes were measured, a WARNING
+	// column when a sensitive host path is bind-mounted.
+	sized, flagged := false, false
+	for _, c := range list {
+		for _, m := range c.Mounts {
+			sized = sized || m.Size != nil
+		}
+		flagged = flagged || c.Flagged()
+	}
+	header := prettytable.Row{"CONTAINER", "TYPE", "SOURCE", "DESTINATION", "RW"}
+	if sized {
+		header = append(header, "SIZE")
+	}
+	if flagged {
+		header = append(header, "WARNING")
+	}
+	tw.AppendHeader(header)
+	// SOURCE and DESTINATION share what is left after the fixed columns.
+	pathMax := 40
+	if width > 0 {
+		pathMax = (width - 24 - 8 - 3 - 16 - boolToInt(sized)*20 - boolToInt(flagged)*33) / 2
+		if pathMax < 16 {
+			pathMax = 16
+		}
+	}
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "CONTAINER", WidthMax: 24, AutoMerge: true},
+		{Name: "TYPE", WidthMax: 8},
+		{Name: "SOURCE", WidthMax: pathMax},
+		{Name: "DESTINATION", WidthMax: pathMax},
+		{Name: "RW", WidthMax: 3},
+		{Name: "SIZE", Align: text.AlignRight, WidthMax: 17},
+		{Name: "WARNING", WidthMax: 30},
+	})
+	// row drops the SIZE and WARNING cells when those columns are hidden.
+	row := func(cells ...interface{}) prettytable.Row {
+		out := prettytable.Row(cells[:5])
+		if sized {
+			out = append(out, cells[5])
+		}
+		if flagged {
+			out = append(out, cells[6])
+		}
+		return out
+	}
+	if len(list) == 0 {
+		tw.AppendFooter(row("no containers", "", "", "", "", "", ""))
+		tw.Render()
+		return nil
+	}
+	for _, c := range list {
+		label := TruncateName(c.Name, noTrunc, 24)
+		if total, ok := c.VolumeBytes(); ok {
+			// The per-container total shows who owns the disk space.
+			label += "\n" + HumanizeBytes(uint64(total))
+		}
+		name := text.Colors{text.FgCyan}.Sprint(label)
+		if len(c.Mounts) == 0 {
+			tw.AppendRow(row(name, "—", "", "", "", "", ""))
+			continue
+		}
+		for _, m := range c.Mounts {
+			source := m.Source
+			if m.Type == "volume" && m.Name != "" {
+				// Volume paths all live under the daemon's data root; the
+				// name is what users recognise.
+				source = m.Name
+			}
+			rw := "rw"
+			if !m.RW {
+				rw = text.Colors{text.FgYellow}.Sprint("ro")
+			}
+			size := ""
+			if m.Size != nil {
+				size = HumanizeBytes(uint64(*m.Size))
+				if m.Limit > 0 {
+					// tmpfs: used / capacity, colored as it fills up.
+					pct := float64(*m.Size) / float64(m.Limit) * 100
+					size = formatPercent(fmt.Sprintf("%s / %s", size, HumanizeBytes(uint64(m.Limit))), pct, 0)
+				}
+			}
+			tw.AppendRow(row(
+				name,
+				m.Type,
+				TruncateName(source, noTrunc, pathMax),
+				TruncateName(m.Destination, noTrunc, pathMax),
+				rw,
+				size,
+				mountWarning(m),
+			))
+		}
+	}
+	tw.Render()
+	return nil
+}
+
+// RenderImages renders local images with their size and the containers
+// using them. With dangling set the title summarises what removing unused
+// untagged images would reclaim.
+func RenderImages(list []dkr.ImageSummary, format OutputFormat, dangling, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	tw := prettytable.NewWriter()
+	if w == nil {
+		tw.SetOutputMirror(os.Stdout)
+	} else {
+		tw.SetOutputMirror(w)
+	}
+	styleI := prettytable.StyleRounded
+	styleI.Options.SeparateRows = true
+	styleI.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
+	tw.SetStyle(styleI)
+	if width := detectTerminalWidth(w); width > 0 {
+		tw.SetAllowedRowLength(width)
+	}
+	if dangling {
+		var reclaim int64
+		inUse := 0
+		for _, img := range list {
+			if img.Reclaimable() {
+				// Layers shared only among these images are freed too, so
+				// the sum of unique sizes is a lower bound.
+				reclaim += img.UniqueSize
+			} else {
+				inUse++
+			}
+		}
+		tw.SetTitle(fmt.Sprintf("whale — dangling images: %d — reclaimable ≥ %s (%d still used by containers) — %s",
+			len(list), HumanizeBytes(uint64(reclaim)), inUse, titleTime(time.Now())))
+	} else {
+		tw.SetTitle(fmt.Sprintf("whale — images: %d — %s", len(list), titleTime(time.Now())))
+	}
+	tw.AppendHeader(prettytable.Row{"ID", "TAGS", "CREATED", "SIZE", "SHARED", "UNIQUE", "USED BY"})
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "ID", WidthMax: 12},
+		{Name: "TAGS", WidthMax: 40},
+		{Name: "CREATED", Align: text.AlignRight, WidthMax: 8},
+		{Name: "SIZE", Align: text.AlignRight, WidthMax: 10},
+		{Name: "SHARED", Align: text.AlignRight, WidthMax: 10},
+		{Name: "UNIQUE", Align: text.AlignRight, WidthMax: 10},
+		{Name: "USED BY", WidthMax: 40},
+	})
+	if len(list) == 0 {
+		tw.AppendFooter(prettytable.Row{"no images", "", "", "", "", "", ""})
+		tw.Render()
+		return nil
+	}
+	for _, img := range list {
+		tags := text.Colors{text.Faint}.Sprint("<none>")
+		if len(img.Tags) > 0 {
+			tags = TruncateName(strings.Join(img.Tags, ", "), noTrunc, 40)
+		}
+		shared := "—"
+		if img.SharedSize > 0 {
+			shared = HumanizeBytes(uint64(img.SharedSize))
+		}
+		tw.AppendRow(prettytable.Row{
+			TruncateID(strings.TrimPrefix(img.ID, "sha256:"), noTrunc),
+			tags,
+			humanAge(img.Created),
+			HumanizeBytes(uint64(img.Size)),
+			shared,
+			text.Colors{text.Bold}.Sprint(HumanizeBytes(uint64(img.UniqueSize))),
+			imageUsers(img),
+		})
+	}
+	tw.Render()
+	return nil
+}
+
+// RenderOutdated lists containers whose image tag has moved on in the
+// registry, followed by those that are current or could not be checked.
+func RenderOutdated(list []dkr.Outdated, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	tw := prettytable.NewWriter()
+	if w == nil {
+		tw.SetOutputMirror(os.Stdout)
+	} else {
+		tw.SetOutputMirror(w)
+	}
+	styleO := prettytable.StyleRounded
+	styleO.Options.SeparateRows = true
+	styleO.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
+	tw.SetStyle(styleO)
+	if width := detectTerminalWidth(w); width > 0 {
+		tw.SetAllowedRowLength(width)
+	}
+	stale := 0
+	for _, o := range list {
+		if o.Stale {
+			stale++
+		}
+	}
+	tw.SetTitle(fmt.Sprintf("whale — outdated images: %d of %d containers — %s", stale, len(list), titleTime(time.Now())))
+	tw.AppendHeader(prettytable.Row{"NAME", "IMAGE", "RUNNING", "LATEST", "STATUS"})
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "NAME", WidthMax: 30},
+		{Name: "IMAGE", WidthMax: 40},
+		{Name: "RUNNING", WidthMax: 19},
+		{Name: "LATEST", WidthMax: 19},
+		{Name: "STATUS", WidthMax: 40},
+	})
+	if len(list) == 0 {
+		tw.AppendFooter(prettytable.Row{"no containers", "", "", "", ""})
+		tw.Render()
+		return nil
+	}
+	sorted := append([]dkr.Outdated(nil), list...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Stale && !sorted[j].Stale })
+	short := func(d string) string {
+		d = strings.TrimPrefix(d, "sha256:")
+		if !noTrunc && len(d) > 12 {
+			d = d[:12]
+		}
+		return d
+	}
+	for _, o := range sorted {
+		status := text.Colors{text.FgGreen}.Sprint("up to date")
+		switch {
+		case o.Stale:
+			status = text.Colors{text.FgYellow, text.Bold}.Sprint("outdated")
+		case o.Skipped != "":
+			status = text.Colors{text.Faint}.Sprint(TruncateName(o.Skipped, noTrunc, 40))
+		}
+		tw.AppendRow(prettytable.Row{
+			TruncateName(o.Name, noTrunc, 30),
+			TruncateName(o.Image, noTrunc, 40),
+			short(o.Running),
+			short(o.Latest),
+			status,
+		})
+	}
+	tw.Render()
+	return nil
+}
+
+// RenderDrift lists differences between a Compose file and the deployed
+// containers, one row per difference.
+func RenderDrift(project string, drift []dkr.Drift, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(drift)
+	}
+	tw := prettytable.NewWriter()
+	if w == nil {
+		tw.SetOutputMirror(os.Stdout)
+	} else {
+		tw.SetOutputMirror(w)
+	}
+	styleD := prettytable.StyleRounded
+	styleD.Options.SeparateRows = true
+	styleD.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
+	tw.SetStyle(styleD)
+	if width := detectTerminalWidth(w); width > 0 {
+		tw.SetAllowedRowLength(width)
+	}
+	tw.SetTitle(fmt.Sprintf("whale — drift in %s: %d — %s", project, len(drift), titleTime(time.Now())))
+	tw.AppendHeader(prettytable.Row{"SERVICE", "CONTAINER", "DRIFT", "WANT", "HAVE"})
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "SERVICE", WidthMax: 20, AutoMerge: true},
+		{Name: "CONTAINER", WidthMax: 30},
+		{Name: "DRIFT", WidthMax: 8},
+		{Name: "WANT", WidthMax: 40},
+		{Name: "HAVE", WidthMax: 40},
+	})
+	if len(drift) == 0 {
+		tw.AppendFooter(prettytable.Row{text.Colors{text.FgGreen}.Sprint("in sync"), "", "", "", ""})
+		tw.Render()
+		return nil
+	}
+	for _, d := range drift {
+		kind := text.Colors{text.FgYellow}.Sprint(d.Kind)
+		if d.Kind == "missing" || d.Kind == "stopped" {
+			kind = text.Colors{text.FgHiRed}.Sprint(d.Kind)
+		}
+		tw.AppendRow(prettytable.Row{
+			text.Colors{text.FgCyan}.Sprint(d.Service),
+			TruncateName(d.Container, noTrunc, 30),
+			kind,
+			TruncateName(d.Want, noTrunc, 40),
+			TruncateName(d.Have, noTrunc, 40),
+		})
+	}
+	tw.Render()
+	return nil
+}
+
+// imageUsers describes who uses an image: running containers, stopped ones,
+// and for dangling images how recently.
+func imageUsers(img dkr.ImageSummary) string {
+	if len(img.Containers) == 0 {
+		if img.Dangling {
+			return text.Colors{text.FgGreen}.Sprint("unused — safe to remove")
+		}
+		return "—"
+	}
+	users := strings.Join(img.Containers, ", ")
+	switch {
+	case img.Running > 0:
+		return text.Colors{text.FgYellow}.Sprintf("%s (running)", users)
+	case !img.LastUsed.IsZero():
+		return fmt.Sprintf("%s (stopped %s ago)", users, humanAge(img.LastUsed))
+	default:
+		return users + " (stopped)"
+	}
+}
+
+// imageTooOld reports whether the container's image is older than maxAge.
+func imageTooOld(s dkr.ContainerSnapshot, maxAge time.Duration) bool {
+	return maxAge > 0 && s.ImageCreated != nil && time.Since(*s.ImageCreated) > maxAge
+}
+
+// humanAge formats the time since t in its largest unit: 45s, 12m, 5h, 3d.
+func humanAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// mountWarning colors a sensitive-path warning: red when the container can
+// write to the path, yellow when it is read-only.
+func mountWarning(m dkr.Mount) string {
+	if m.Warning == "" {
+		return ""
+	}
+	if m.RW {
+		return text.Colors{text.FgHiRed}.Sprint("⚠ " + m.Warning)
+	}
+	return text.Colors{text.FgYellow}.Sprint("⚠ " + m.Warning + " (ro)")
+}
+
+// RenderNotices prints die/OOM notices below the watch table, newest last.
+func RenderNotices(w io.Writer, notices []dkr.Notice) {
+	for _, n := range notices {
+		var what string
+		color := text.Colors{text.FgHiRed}
+		switch {
+		case n.OOM && n.Died:
+			what = fmt.Sprintf("OOM-killed, exited (%d)", n.ExitCode)
+		case n.OOM:
+			what = "OOM kill inside the container (still running)"
+			color = text.Colors{text.FgYellow}
+		case n.ExitCode == 0:
+			what = "exited (0)"
+			color = text.Colors{text.Faint}
+		default:
+			what = fmt.Sprintf("died, exited (%d)", n.ExitCode)
+		}
+		fmt.Fprintf(w, "%s  %s  %s\n", eventTime(n.Time), n.Name, color.Sprint(what))
+	}
+}
+
+// RenderMembershipChanges lists recent network connects and disconnects
+// below the network-watch table.
+func RenderMembershipChanges(w io.Writer, changes []dkr.MembershipChange) {
+	for _, c := range changes {
+		what := text.Colors{text.FgGreen}.Sprint("connected to")
+		if !c.Connected {
+			what = text.Colors{text.FgYellow}.Sprint("disconnected from")
+		}
+		line := fmt.Sprintf("%s  %s  %s %s", eventTime(c.Time), c.Container, what, c.Network)
+		if c.Gone {
+			line += text.Colors{text.Faint}.Sprint(" (container gone)")
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// PrintDelimiter writes a timestamped separator line. Used by watch modes with
+// --no-clear so consecutive frames remain distinguishable in logs.
+func PrintDelimiter(w io.Writer, t time.Time) {
+	if w == nil {
+		w = os.Stdout
+	}
+	_, _ = fmt.Fprintf(w, "--- %s ---\n", inZone(t).Format(time.RFC3339))
+}
+
 func percentageBar(pct float64, width int) string {
 	if pct < 0 {
 		pct = 0
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Progress draws a "collecting stats n/total…" spinner on a terminal once an
+// operation has taken longer than a delay, so slow collections don't look hung.
+// It is a no-op when the writer is not a terminal.
+type Progress struct {
+	w     io.Writer
+	done  atomic.Int64
+	total atomic.Int64
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// StartProgress begins tracking progress, drawing on w (stderr when nil) after delay.
+func StartProgress(w io.Writer, delay time.Duration) *Progress {
+	if w == nil {
+		w = os.Stderr
+	}
+	p := &Progress{w: w, stop: make(chan struct{})}
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return p
+	}
+	p.wg.Add(1)
+	go p.loop(delay)
+	return p
+}
+
+// Update records that done of total items are finished. Safe for concurrent use.
+func (p *Progress) Update(done, total int) {
+	p.done.Store(int64(done))
+	p.total.Store(int64(total))
+}
+
+// Stop ends the spinner and erases its line so following output starts clean.
+func (p *Progress) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+func (p *Progress) loop(delay time.Duration) {
+	defer p.wg.Done()
+	select {
+	case <-time.After(delay):
+	case <-p.stop:
+		return
+	}
+	frames := []rune{'⠋', '⠙', '⠹', '⠸', '⠼', '⠴', '⠦', '⠧', '⠇', '⠏'}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		line := fmt.Sprintf("%c collecting stats", frames[i%len(frames)])
+		if total := p.total.Load(); total > 0 {
+			line += fmt.Sprintf(" %d/%d", p.done.Load(), total)
+		}
+		// Carriage return + erase line keeps the spinner on a single row.
+		_, _ = fmt.Fprintf(p.w, "\r\x1b[2K%s…", line)
+		select {
+		case <-ticker.C:
+		case <-p.stop:
+			_, _ = io.WriteString(p.w, "\r\x1b[2K")
+			return
+		}
+	}
+}
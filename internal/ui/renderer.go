@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// Renderer writes a container list in one output format.
+type Renderer interface {
+	Render(w io.Writer, snaps []dkr.ContainerSnapshot, opts RenderOptions) error
+}
+
+// RendererFunc adapts a plain function to Renderer.
+type RendererFunc func(w io.Writer, snaps []dkr.ContainerSnapshot, opts RenderOptions) error
+
+func (f RendererFunc) Render(w io.Writer, snaps []dkr.ContainerSnapshot, opts RenderOptions) error {
+	return f(w, snaps, opts)
+}
+
+// RendererFactory builds a Renderer from the argument part of a format spec
+// ("name:arg"), e.g. the template of "template:{{.Name}}".
+type RendererFactory func(arg string) (Renderer, error)
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]RendererFactory{}
+)
+
+// RegisterRenderer makes a format available to --format under name. It
+// panics on duplicates, like export.Register.
+func RegisterRenderer(name string, f RendererFactory) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	if _, dup := renderers[name]; dup {
+		panic("ui: RegisterRenderer called twice for " + name)
+	}
+	renderers[name] = f
+}
+
+// NewRenderer builds the renderer for a format spec of the form "name" or
+// "name:arg". Names are case-insensitive; the argument is kept as is.
+func NewRenderer(spec string) (Renderer, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+	renderersMu.RLock()
+	f, ok := renderers[strings.ToLower(name)]
+	renderersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(RendererNames(), ", "))
+	}
+	return f(arg)
+}
+
+// RendererNames lists registered format names in sorted order.
+func RendererNames() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	names := make([]string, 0, len(renderers))
+	for n := range renderers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// plain registers a renderer that takes no argument.
+func plain(name string, r RendererFunc) {
+	RegisterRenderer(name, func(arg string) (Renderer, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("format %s takes no argument", name)
+		}
+		return r, nil
+	})
+}
+
+func init() {
+	plain(string(FormatTable), renderTableFormat)
+	plain(string(FormatJSON), func(w io.Writer, snaps []dkr.ContainerSnapshot, opts RenderOptions) error {
+		return renderJSON(snaps, opts, w)
+	})
+	plain(string(FormatCSV), renderCSV)
+	RegisterRenderer(string(FormatTemplate), newTemplateRenderer)
+}
+
+// renderTableFormat is the human-readable view: the table, or cards on
+// narrow terminals, followed by a note on failed stats reads.
+func renderTableFormat(w io.Writer, snaps []dkr.ContainerSnapshot, opts RenderOptions) error {
+	if opts.NoStats {
+		renderListTable(snaps, opts, w)
+		return nil
+	}
+	if useCards(opts.Layout, w) {
+		renderCards(snaps, opts, w)
+	} else {
+		renderTable(snaps, opts, w)
+	}
+	if n := StatsFailures(snaps); n > 0 {
+		fmt.Fprintln(w, text.Colors{text.FgYellow}.Sprintf("%d of %d containers failed stats collection (--debug for details)", n, len(snaps)))
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	prettytable "github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/therapys/whale/internal/scan"
+)
+
+// ScanRow is one scanned image and the containers running it, for the
+// `whale scan` overview.
+type ScanRow struct {
+	Image      string       `json:"image"`
+	Containers []string     `json:"containers"`
+	Result     *scan.Result `json:"result,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// RenderScanSummary renders per-image CVE counts by severity, worst first.
+func RenderScanSummary(rows []ScanRow, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+	tw := newScanTable(w)
+	tw.SetTitle(fmt.Sprintf("whale — vulnerabilities: %d images — %s", len(rows), titleTime(time.Now())))
+	header := prettytable.Row{"IMAGE", "CONTAINERS"}
+	for _, sev := range scan.Severities {
+		header = append(header, sev[:4])
+	}
+	tw.AppendHeader(header)
+	configs := []prettytable.ColumnConfig{
+		{Name: "IMAGE", WidthMax: 40},
+		{Name: "CONTAINERS", WidthMax: 30},
+	}
+	for _, sev := range scan.Severities {
+		configs = append(configs, prettytable.ColumnConfig{Name: sev[:4], Align: text.AlignRight, WidthMax: 6})
+	}
+	tw.SetColumnConfigs(configs)
+	if len(rows) == 0 {
+		footer := make(prettytable.Row, len(header))
+		footer[0] = "no running containers"
+		tw.AppendFooter(footer)
+		tw.Render()
+		return nil
+	}
+	for _, r := range rows {
+		row := prettytable.Row{TruncateName(r.Image, noTrunc, 40), TruncateName(strings.Join(r.Containers, ", "), noTrunc, 30)}
+		if r.Result == nil {
+			row = append(row, text.Colors{text.FgHiRed}.Sprint(TruncateName("error: "+r.Error, noTrunc, 40)))
+			for len(row) < len(header) {
+				row = append(row, "")
+			}
+			tw.AppendRow(row)
+			continue
+		}
+		for _, sev := range scan.Severities {
+			row = append(row, severityCell(sev, r.Result.Counts[sev]))
+		}
+		tw.AppendRow(row)
+	}
+	tw.Render()
+	return nil
+}
+
+// RenderScan renders every finding for one image.
+func RenderScan(r *scan.Result, format OutputFormat, noTrunc bool, w io.Writer) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	tw := newScanTable(w)
+	var counts []string
+	for _, sev := range scan.Severities {
+		if n := r.Counts[sev]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, strings.ToLower(sev)))
+		}
+	}
+	if len(counts) == 0 {
+		counts = []string{"no findings"}
+	}
+	tw.SetTitle(fmt.Sprintf("whale — %s — %s (%s, scanned %s ago)", r.Image, strings.Join(counts, ", "), r.Tool, humanAge(r.ScannedAt)))
+	tw.AppendHeader(prettytable.Row{"SEVERITY", "ID", "PACKAGE", "INSTALLED", "FIXED IN"})
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "SEVERITY", WidthMax: 8},
+		{Name: "ID", WidthMax: 20},
+		{Name: "PACKAGE", WidthMax: 30},
+		{Name: "INSTALLED", WidthMax: 20},
+		{Name: "FIXED IN", WidthMax: 20},
+	})
+	for _, v := range r.Vulns {
+		fixed := v.Fixed
+		if fixed == "" {
+			fixed = text.Colors{text.Faint}.Sprint("—")
+		}
+		tw.AppendRow(prettytable.Row{
+			severityCell(v.Severity, -1),
+			v.ID,
+			TruncateName(v.Package, noTrunc, 30),
+			TruncateName(v.Installed, noTrunc, 20),
+			fixed,
+		})
+	}
+	tw.Render()
+	return nil
+}
+
+func newScanTable(w io.Writer) prettytable.Writer {
+	tw := prettytable.NewWriter()
+	if w == nil {
+		tw.SetOutputMirror(os.Stdout)
+	} else {
+		tw.SetOutputMirror(w)
+	}
+	style := prettytable.StyleRounded
+	style.Options.SeparateRows = true
+	style.Color.Header = text.Colors{text.FgHiWhite, text.Bold}
+	tw.SetStyle(style)
+	if width := detectTerminalWidth(w); width > 0 {
+		tw.SetAllowedRowLength(width)
+	}
+	return tw
+}
+
+// severityCell colors a severity name, or with n >= 0 a count for it.
+func severityCell(sev string, n int) string {
+	cell := sev
+	if n >= 0 {
+		if n == 0 {
+			return text.Colors{text.Faint}.Sprint("0")
+		}
+		cell = fmt.Sprintf("%d", n)
+	}
+	switch sev {
+	case "CRITICAL":
+		return text.Colors{text.FgHiRed, text.Bold}.Sprint(cell)
+	case "HIGH":
+		return text.Colors{text.FgHiRed}.Sprint(cell)
+	case "MEDIUM":
+		return text.Colors{text.FgYellow}.Sprint(cell)
+	default:
+		return cell
+	}
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	prettytable "github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// RenderSessionSummary prints what a watch session measured: per-container
+// CPU and memory ranges, the I/O seen while watching and any state changes.
+func RenderSessionSummary(w io.Writer, sum dkr.SessionSummary) {
+	tw := newScanTable(w)
+	tw.SetTitle(fmt.Sprintf("whale — session summary: %s, %d refreshes (%s–%s)",
+		sum.Duration().Round(time.Second), sum.Refreshes, eventTime(sum.Start), eventTime(sum.End)))
+	tw.AppendHeader(prettytable.Row{"NAME", "SAMPLES", "CPU % MIN/AVG/MAX", "MEM MIN/AVG/MAX", "NET I/O", "BLOCK I/O"})
+	tw.SetColumnConfigs([]prettytable.ColumnConfig{
+		{Name: "NAME", WidthMax: 30},
+		{Name: "SAMPLES", Align: text.AlignRight},
+	})
+	if len(sum.Containers) == 0 {
+		tw.AppendFooter(prettytable.Row{"no stats collected", "", "", "", "", ""})
+	}
+	for _, c := range sum.Containers {
+		tw.AppendRow(prettytable.Row{
+			c.Name,
+			c.Samples,
+			fmt.Sprintf("%.1f / %.1f / %s", c.CPUMin, c.CPUAvg, formatPercent(fmt.Sprintf("%.1f", c.CPUMax), c.CPUMax, 0)),
+			fmt.Sprintf("%s / %s / %s", HumanizeBytes(c.MemMin), HumanizeBytes(c.MemAvg), HumanizeBytes(c.MemMax)),
+			printableIO(c.NetRx, c.NetTx),
+			printableIO(c.BlockRead, c.BlockWrite),
+		})
+	}
+	tw.Render()
+	if len(sum.Changes) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "State changes:")
+	for _, c := range sum.Changes {
+		what := fmt.Sprintf("%s → %s", c.From, c.To)
+		if c.From == "" {
+			what = "appeared, " + c.To
+		}
+		fmt.Fprintf(w, "  %s  %s  %s\n", eventTime(c.Time), c.Name, what)
+	}
+}
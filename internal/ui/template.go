@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/template"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// newTemplateRenderer parses the argument of "template:<text>" as a Go
+// text/template and executes it once per container, each followed by a
+// newline. Templates run against dkr.Row, so they see the same fields as
+// --format=json:
+//
+//	whale --format 'template:{{.Name}} {{.CPUPercent}}'
+func newTemplateRenderer(arg string) (Renderer, error) {
+	if arg == "" {
+		return nil, errors.New("format template needs a template, e.g. template:{{.Name}}")
+	}
+	tmpl, err := template.New("format").Parse(arg)
+	if err != nil {
+		return nil, fmt.Errorf("format template: %w", err)
+	}
+	return RendererFunc(func(w io.Writer, snaps []dkr.ContainerSnapshot, _ RenderOptions) error {
+		for _, s := range snaps {
+			if err := tmpl.Execute(w, dkr.NewRow(s)); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}), nil
+}
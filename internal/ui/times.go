@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"time"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+// TimeStyle selects how titles and event lines write timestamps.
+type TimeStyle string
+
+const (
+	// TimeClock writes the time of day: 3:04PM in titles, 15:04:05 for
+	// events.
+	TimeClock TimeStyle = "clock"
+	// TimeAbsolute writes the date, time and zone: 2006-01-02 15:04:05 CEST.
+	TimeAbsolute TimeStyle = "absolute"
+	// TimeRelative writes events as an age (2m ago) and titles as the time
+	// since whale started (+5m).
+	TimeRelative TimeStyle = "relative"
+)
+
+// timeStyle and timeUTC are set once at startup by SetTimeFormat.
+var (
+	timeStyle = TimeClock
+	timeUTC   bool
+	started   = time.Now()
+)
+
+// SetTimeFormat changes how timestamps are written from now on. With utc,
+// clock and absolute times are in UTC instead of the local zone, for
+// comparing against server logs.
+func SetTimeFormat(style TimeStyle, utc bool) {
+	if style == "" {
+		style = TimeClock
+	}
+	timeStyle, timeUTC = style, utc
+}
+
+// inZone converts t to the zone timestamps are written in.
+func inZone(t time.Time) time.Time {
+	if timeUTC {
+		return t.UTC()
+	}
+	return t.Local()
+}
+
+// titleTime writes t, usually now, for a table title.
+func titleTime(t time.Time) string {
+	switch timeStyle {
+	case TimeAbsolute:
+		return inZone(t).Format("2006-01-02 15:04:05 MST")
+	case TimeRelative:
+		return "+" + dkr.ShortDuration(t.Sub(started))
+	}
+	if timeUTC {
+		return inZone(t).Format(time.Kitchen) + " UTC"
+	}
+	return t.Format(time.Kitchen)
+}
+
+// eventTime writes when something happened, for notices and change lists.
+func eventTime(t time.Time) string {
+	switch timeStyle {
+	case TimeAbsolute:
+		return inZone(t).Format("2006-01-02 15:04:05 MST")
+	case TimeRelative:
+		return humanAge(t) + " ago"
+	}
+	return inZone(t).Format("15:04:05")
+}
+
+// logTime writes a log line's timestamp: with its date unless relative,
+// since matches can span days.
+func logTime(t time.Time) string {
+	if timeStyle == TimeRelative {
+		return humanAge(t) + " ago"
+	}
+	return inZone(t).Format(time.DateTime)
+}
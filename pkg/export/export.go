@@ -0,0 +1,114 @@
+// Package export ships collected snapshots to external sinks. Exporters are
+// looked up by name in a registry: whale registers jsonl and exec, and
+// programs embedding whale can Register their own.
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/therapys/whale/internal/store"
+	"github.com/therapys/whale/pkg/whale"
+)
+
+// Exporter ships a collected set of snapshots to some sink. Export is
+// called once per collection (every refresh in watch mode).
+type Exporter interface {
+	Export(ctx context.Context, snaps []whale.ContainerSnapshot) error
+}
+
+// Factory builds an Exporter from the argument part of a spec ("name:arg").
+type Factory func(arg string) (Exporter, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes an exporter available under name. It panics on duplicates,
+// like database/sql drivers, since that is a programming error.
+func Register(name string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := factories[name]; dup {
+		panic("export: Register called twice for " + name)
+	}
+	factories[name] = f
+}
+
+// New builds an exporter from a spec of the form "name" or "name:arg".
+func New(spec string) (Exporter, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+	mu.RLock()
+	f, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown exporter %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return f(arg)
+}
+
+// Names lists registered exporter names in sorted order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for n := range factories {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("jsonl", func(path string) (Exporter, error) {
+		if path == "" {
+			return nil, fmt.Errorf("jsonl exporter needs a file path (jsonl:/path/file.jsonl)")
+		}
+		return jsonlExporter{path: path}, nil
+	})
+	Register("exec", func(path string) (Exporter, error) {
+		if path == "" {
+			return nil, fmt.Errorf("exec exporter needs a command path (exec:/path/to/cmd)")
+		}
+		return execExporter{path: path}, nil
+	})
+}
+
+// jsonlExporter appends untagged records in the snapshot store format, so
+// exported files can be read back like `whale snapshot` files.
+type jsonlExporter struct{ path string }
+
+func (e jsonlExporter) Export(_ context.Context, snaps []whale.ContainerSnapshot) error {
+	return store.Append(e.path, store.Snapshot{Time: time.Now().UTC(), Containers: snaps})
+}
+
+// execExporter pipes the snapshots as a JSON array to a command's stdin.
+type execExporter struct{ path string }
+
+func (e execExporter) Export(ctx context.Context, snaps []whale.ContainerSnapshot) error {
+	data, err := json.Marshal(snaps)
+	if err != nil {
+		return err
+	}
+	cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(cctx, e.path)
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
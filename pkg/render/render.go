@@ -0,0 +1,32 @@
+// Package render adds output formats to whale's --format for the container
+// list. Formats are looked up by name in a registry: whale registers table,
+// json, csv and template, and programs embedding whale can Register their
+// own. The types are aliases of whale's own renderers, so a format
+// registered here is the same as a built-in one.
+package render
+
+import "github.com/therapys/whale/internal/ui"
+
+type (
+	// Renderer writes a container list in one output format.
+	Renderer = ui.Renderer
+	// Func adapts a plain function to Renderer.
+	Func = ui.RendererFunc
+	// Factory builds a Renderer from the argument part of a format spec
+	// ("name:arg").
+	Factory = ui.RendererFactory
+	// Options are the display settings from the command line (sorting,
+	// columns, truncation...), passed to every Render call.
+	Options = ui.RenderOptions
+)
+
+// Register makes a format available to --format under name. It panics on
+// duplicates.
+func Register(name string, f Factory) { ui.RegisterRenderer(name, f) }
+
+// New builds the renderer for a format spec of the form "name" or
+// "name:arg".
+func New(spec string) (Renderer, error) { return ui.NewRenderer(spec) }
+
+// Names lists registered format names in sorted order.
+func Names() []string { return ui.RendererNames() }
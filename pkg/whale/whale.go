@@ -0,0 +1,54 @@
+// Package whale is the public face of whale's collection types, for
+// programs that embed it and for the exporters they plug in (see
+// pkg/export). The types are aliases of whale's own, so values pass between
+// the two without conversion.
+package whale
+
+import (
+	"context"
+
+	"github.com/docker/docker/client"
+
+	dkr "github.com/therapys/whale/internal/docker"
+)
+
+type (
+	// ContainerSnapshot is one container's listing details and metrics
+	// from a single collection.
+	ContainerSnapshot = dkr.ContainerSnapshot
+	// Row is the serialized form of a ContainerSnapshot, as written by
+	// --format=json, the snapshot store and the built-in exporters.
+	Row = dkr.Row
+	// NetworkAttachment is a container's address on one network.
+	NetworkAttachment = dkr.NetworkAttachment
+	// Mount is a volume, bind mount or tmpfs mount of a container.
+	Mount = dkr.Mount
+	// HostInfo describes the Docker host a collection came from.
+	HostInfo = dkr.HostInfo
+	// ContainerNetInfo is a container as grouped by network.
+	ContainerNetInfo = dkr.ContainerNetInfo
+
+	// Collector is a container runtime whale can read from. Implement it
+	// to collect from something other than Docker, and ExitReader and
+	// Sampler for exit codes of stopped containers and --sample.
+	Collector = dkr.Collector
+	// ExitReader reads a stopped container's exit code and finish time.
+	ExitReader = dkr.ExitReader
+	// Sampler measures CPU over a chosen window.
+	Sampler = dkr.Sampler
+	// CollectOptions configures Collect; Collector is required.
+	CollectOptions = dkr.CollectOptions
+)
+
+// NewDockerCollector returns the Collector whale uses, reading from the
+// Docker daemon behind cli.
+func NewDockerCollector(cli *client.Client) Collector { return dkr.NewDockerCollector(cli, nil) }
+
+// Collect lists containers through opts.Collector and takes a stats reading
+// of each running one, as a whale refresh does.
+func Collect(ctx context.Context, opts CollectOptions) ([]ContainerSnapshot, error) {
+	return dkr.CollectSnapshots(ctx, opts)
+}
+
+// NewRow converts s to its serialized form.
+func NewRow(s ContainerSnapshot) Row { return dkr.NewRow(s) }